@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// hiddenAbilityChance is how often a wild pokemon turns up with its hidden
+// ability instead of one of its regular ones.
+const hiddenAbilityChance = 0.05
+
+func fetchAbility(c *config, name string) (Ability, error) {
+	ability := Ability{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/ability/%s", name)
+	if err := fetchJSON(c, url, &ability); err != nil {
+		return ability, fmt.Errorf("failed to fetch ability %s: %v", name, err)
+	}
+	return ability, nil
+}
+
+// rollAbility picks the ability a wild pokemon has: one of its regular
+// abilities, or rarely its hidden one.
+func rollAbility(pokemon Pokemon) string {
+	regular := []string{}
+	hidden := []string{}
+	for _, a := range pokemon.Abilities {
+		if a.IsHidden {
+			hidden = append(hidden, a.Ability.Name)
+		} else {
+			regular = append(regular, a.Ability.Name)
+		}
+	}
+	if len(hidden) > 0 && (len(regular) == 0 || rand.Float64() < hiddenAbilityChance) {
+		return hidden[rand.Intn(len(hidden))]
+	}
+	if len(regular) == 0 {
+		return ""
+	}
+	return regular[rand.Intn(len(regular))]
+}
+
+func printAbilities(w io.Writer, pokemon *CaughtPokemon) {
+	if pokemon.Ability != "" {
+		fmt.Fprintf(w, "Ability: %s\n", pokemon.Ability)
+	}
+	fmt.Fprintf(w, "Abilities:\n")
+	for _, a := range pokemon.Abilities {
+		if a.IsHidden {
+			fmt.Fprintf(w, "  %s (hidden)\n", a.Ability.Name)
+		} else {
+			fmt.Fprintf(w, "  %s\n", a.Ability.Name)
+		}
+	}
+}
+
+func commandAbility(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("please provide an ability name")
+	}
+	language := "en"
+	// Language codes like zh-Hans are case sensitive, so only they keep
+	// their case.
+	if len(args) == 4 && strings.ToLower(args[2]) == "--lang" {
+		language = args[3]
+	} else if len(args) > 2 {
+		return fmt.Errorf("usage: ability <name> [--lang <code>]")
+	}
+	ability, err := fetchAbility(c, strings.ToLower(args[1]))
+	if err != nil {
+		return err
+	}
+
+	name := ability.Name
+	for _, n := range ability.Names {
+		if n.Language.Name == language {
+			name = n.Name
+		}
+	}
+	effect := ""
+	for _, entry := range ability.EffectEntries {
+		if entry.Language.Name == language || (effect == "" && entry.Language.Name == "en") {
+			effect = entry.Effect
+		}
+	}
+	fmt.Fprintf(c.Out, "Name: %s\n", name)
+	fmt.Fprintf(c.Out, "Generation: %s\n", ability.Generation.Name)
+	if effect != "" {
+		fmt.Fprintf(c.Out, "Effect: %s\n", effect)
+	}
+	fmt.Fprintln(c.Out, "Pokemon with this ability:")
+	for _, p := range ability.Pokemon {
+		if p.IsHidden {
+			fmt.Fprintf(c.Out, "- %s (hidden)\n", p.Pokemon.Name)
+		} else {
+			fmt.Fprintf(c.Out, "- %s\n", p.Pokemon.Name)
+		}
+	}
+	return nil
+}
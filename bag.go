@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+const (
+	startingPokeBalls = 10
+	startingMoney     = 3000
+	// maxPurchase keeps a purchase's total well clear of overflowing.
+	maxPurchase = 999
+)
+
+// martStock is what the mart sells; prices are the item costs from PokeAPI.
+var martStock = []string{"poke-ball", "great-ball", "ultra-ball", "potion", "super-potion", "hyper-potion"}
+
+// ballBonus multiplies the chance of a catch for each kind of ball.
+var ballBonus = map[string]float64{
+	"poke-ball":   1,
+	"great-ball":  1.5,
+	"ultra-ball":  2,
+	"master-ball": 255,
+}
+
+// potionHealing is how much HP each potion restores in battle.
+var potionHealing = map[string]int{
+	"potion":       20,
+	"super-potion": 60,
+	"hyper-potion": 120,
+	"max-potion":   9999,
+}
+
+func fetchItem(c *config, name string) (Item, error) {
+	item := Item{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/item/%s", name)
+	if err := fetchJSON(c, url, &item); err != nil {
+		return item, fmt.Errorf("failed to fetch item %s: %v", name, err)
+	}
+	return item, nil
+}
+
+func addItem(c *config, name string, quantity int) {
+	c.Bag[name] += quantity
+}
+
+// useItem takes one of an item out of the bag, reporting false when there
+// was none left.
+func useItem(c *config, name string) bool {
+	if c.Bag[name] <= 0 {
+		return false
+	}
+	c.Bag[name]--
+	if c.Bag[name] == 0 {
+		delete(c.Bag, name)
+	}
+	return true
+}
+
+func earnMoney(c *config, amount int) {
+	c.Money += amount
+}
+
+// rewardExploring gives the trainer a little money for exploring an area.
+func rewardExploring(c *config) {
+	amount := 10 * (1 + rand.Intn(10))
+	earnMoney(c, amount)
+	fmt.Fprintf(c.Out, "You found ₽%d while exploring.\n", amount)
+}
+
+func commandBag(c *config, args []string) error {
+	fmt.Fprintf(c.Out, "Money: ₽%d\n", c.Money)
+	if len(c.Bag) == 0 {
+		fmt.Fprintln(c.Out, "Your bag is empty.")
+		return nil
+	}
+	names := []string{}
+	for name := range c.Bag {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(c.Out, "Your bag:")
+	for _, name := range names {
+		fmt.Fprintf(c.Out, "- %s x%d\n", name, c.Bag[name])
+	}
+	return nil
+}
+
+func commandMart(c *config, args []string) error {
+	if len(args) == 1 {
+		fmt.Fprintln(c.Out, "Welcome to the Poke Mart!")
+		for _, name := range martStock {
+			item, err := fetchItem(c, name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(c.Out, "- %-14s ₽%d\n", item.Name, item.Cost)
+		}
+		fmt.Fprintf(c.Out, "You have ₽%d. Use mart buy <item> [quantity] to buy something.\n", c.Money)
+		return nil
+	}
+	if args[1] != "buy" || len(args) < 3 || len(args) > 4 {
+		return fmt.Errorf("usage: mart [buy <item> [quantity]]")
+	}
+	name := args[2]
+	quantity := 1
+	if len(args) == 4 {
+		n, err := strconv.Atoi(args[3])
+		if err != nil || n < 1 || n > maxPurchase {
+			return fmt.Errorf("please provide a quantity from 1 to %d", maxPurchase)
+		}
+		quantity = n
+	}
+	stocked := false
+	for _, stock := range martStock {
+		if stock == name {
+			stocked = true
+		}
+	}
+	if !stocked {
+		return fmt.Errorf("the mart does not sell %s", name)
+	}
+	item, err := fetchItem(c, name)
+	if err != nil {
+		return err
+	}
+	total := item.Cost * quantity
+	if total > c.Money {
+		return fmt.Errorf("you need ₽%d but only have ₽%d", total, c.Money)
+	}
+	c.Money -= total
+	addItem(c, item.Name, quantity)
+	fmt.Fprintf(c.Out, "You bought %d %s for ₽%d.\n", quantity, item.Name, total)
+	return nil
+}
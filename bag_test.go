@@ -0,0 +1,30 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+func TestMartQuantity(t *testing.T) {
+	c := &config{Cache: pokecache.NewCache(time.Hour), Out: io.Discard, Money: 1000, Bag: map[string]int{}}
+	c.Cache.Add("https://pokeapi.co/api/v2/item/poke-ball", []byte(`{"name":"poke-ball","cost":200}`))
+	for _, quantity := range []string{"46116860184273880", "0", "-1", "1000"} {
+		err := commandMart(c, []string{"mart", "buy", "poke-ball", quantity})
+		if err == nil || !strings.Contains(err.Error(), "quantity") {
+			t.Errorf("expected %s to be refused, got %v", quantity, err)
+		}
+	}
+	if err := commandMart(c, []string{"mart", "buy", "poke-ball", "999"}); err == nil {
+		t.Errorf("expected 999 poke-balls to be too expensive")
+	}
+	if err := commandMart(c, []string{"mart", "buy", "poke-ball", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Money != 0 || c.Bag["poke-ball"] != 5 {
+		t.Errorf("expected to spend everything on 5 poke-balls, have ₽%d and %d", c.Money, c.Bag["poke-ball"])
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// battlePrize is the money won per level of a defeated wild pokemon.
+const battlePrize = 20
+
+// battler is one side of a battle. HP only lasts for the battle; caught
+// pokemon always start a battle fully healed.
+type battler struct {
+	Name           string
+	Level          int
+	Types          []string
+	Stats          map[string]int
+	HP             int
+	Moves          []Move
+	BaseExperience int
+}
+
+// battleRNG is where a battle gets its luck from. Wild battles use the
+// global source; LAN battles share a seeded one so both sides roll the same.
+type battleRNG interface {
+	Intn(n int) int
+	Float64() float64
+}
+
+type globalRNG struct{}
+
+func (globalRNG) Intn(n int) int   { return rand.Intn(n) }
+func (globalRNG) Float64() float64 { return rand.Float64() }
+
+// heal restores up to amount HP and returns how much was actually restored.
+func heal(b *battler, amount int) int {
+	before := b.HP
+	b.HP += amount
+	if b.HP > b.Stats["hp"] {
+		b.HP = b.Stats["hp"]
+	}
+	return b.HP - before
+}
+
+func fetchType(c *config, name string) (PokemonType, error) {
+	pokemonType := PokemonType{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/type/%s", name)
+	if err := fetchJSON(c, url, &pokemonType); err != nil {
+		return pokemonType, fmt.Errorf("failed to fetch type %s: %v", name, err)
+	}
+	return pokemonType, nil
+}
+
+func newBattler(c *config, pokemon Pokemon, level int, stats map[string]int, moves []string) (*battler, error) {
+	b := &battler{
+		Name:           pokemon.Name,
+		Level:          level,
+		Stats:          stats,
+		BaseExperience: pokemon.BaseExperience,
+	}
+	b.HP = b.Stats["hp"]
+	for _, t := range pokemon.Types {
+		b.Types = append(b.Types, t.Type.Name)
+	}
+	if len(moves) == 0 {
+		moves = []string{"struggle"}
+	}
+	for _, name := range moves {
+		move, err := fetchMove(c, name)
+		if err != nil {
+			return nil, err
+		}
+		b.Moves = append(b.Moves, move)
+	}
+	return b, nil
+}
+
+// typeEffectiveness returns the damage multiplier of an attacking type
+// against a pokemon with the given defending types.
+func typeEffectiveness(c *config, attackType string, defendTypes []string) (float64, error) {
+	attack, err := fetchType(c, attackType)
+	if err != nil {
+		return 1, err
+	}
+	multiplier := 1.0
+	for _, defend := range defendTypes {
+		for _, t := range attack.DamageRelations.DoubleDamageTo {
+			if t.Name == defend {
+				multiplier *= 2
+			}
+		}
+		for _, t := range attack.DamageRelations.HalfDamageTo {
+			if t.Name == defend {
+				multiplier *= 0.5
+			}
+		}
+		for _, t := range attack.DamageRelations.NoDamageTo {
+			if t.Name == defend {
+				multiplier = 0
+			}
+		}
+	}
+	return multiplier, nil
+}
+
+// calcDamage is the mainline damage formula; modifier covers STAB, type
+// effectiveness, critical hits and the random roll.
+func calcDamage(level, power, attack, defense int, modifier float64) int {
+	if defense < 1 {
+		defense = 1
+	}
+	base := (2*level/5+2)*power*attack/defense/50 + 2
+	damage := int(float64(base) * modifier)
+	if damage < 1 && modifier > 0 {
+		damage = 1
+	}
+	return damage
+}
+
+// movesFirst reports whether a attacking with moveA acts before b attacking
+// with moveB: higher priority first, then higher speed, ties broken randomly.
+func movesFirst(rng battleRNG, a, b *battler, moveA, moveB Move) bool {
+	if moveA.Priority != moveB.Priority {
+		return moveA.Priority > moveB.Priority
+	}
+	if a.Stats["speed"] != b.Stats["speed"] {
+		return a.Stats["speed"] > b.Stats["speed"]
+	}
+	return rng.Intn(2) == 0
+}
+
+func useMove(c *config, rng battleRNG, attacker, defender *battler, move Move) error {
+	fmt.Fprintf(c.Out, "%s used %s!\n", attacker.Name, move.Name)
+	if move.Accuracy > 0 && rng.Intn(100) >= move.Accuracy {
+		fmt.Fprintf(c.Out, "%s's attack missed!\n", attacker.Name)
+		return nil
+	}
+	if move.Power == 0 {
+		fmt.Fprintln(c.Out, "But nothing happened!")
+		return nil
+	}
+	effectiveness, err := typeEffectiveness(c, move.Type.Name, defender.Types)
+	if err != nil {
+		return err
+	}
+	if effectiveness == 0 {
+		fmt.Fprintf(c.Out, "It doesn't affect %s...\n", defender.Name)
+		return nil
+	}
+	modifier := effectiveness * (0.85 + rng.Float64()*0.15)
+	for _, t := range attacker.Types {
+		if t == move.Type.Name {
+			modifier *= 1.5
+		}
+	}
+	critical := rng.Intn(24) == 0
+	if critical {
+		modifier *= 1.5
+	}
+	attack, defense := attacker.Stats["attack"], defender.Stats["defense"]
+	if move.DamageClass.Name == "special" {
+		attack, defense = attacker.Stats["special-attack"], defender.Stats["special-defense"]
+	}
+	damage := calcDamage(attacker.Level, move.Power, attack, defense, modifier)
+	defender.HP -= damage
+	if defender.HP < 0 {
+		defender.HP = 0
+	}
+	if critical {
+		fmt.Fprintln(c.Out, "A critical hit!")
+	}
+	if effectiveness > 1 {
+		fmt.Fprintln(c.Out, "It's super effective!")
+	} else if effectiveness < 1 {
+		fmt.Fprintln(c.Out, "It's not very effective...")
+	}
+	return nil
+}
+
+// wildLevel picks the level of a wild pokemon from the encounter data of the
+// last explored area for the session's version, falling back to a low level
+// when it was not seen there.
+func wildLevel(c *config, name string) int {
+	if c.Area != nil {
+		for _, encounter := range c.Area.PokemonEncounters {
+			if encounter.Pokemon.Name != name {
+				continue
+			}
+			for _, details := range encounter.VersionDetails {
+				if details.Version.Name != c.Version || len(details.EncounterDetails) == 0 {
+					continue
+				}
+				min, max := maxLevel, 1
+				for _, detail := range details.EncounterDetails {
+					if detail.MinLevel < min {
+						min = detail.MinLevel
+					}
+					if detail.MaxLevel > max {
+						max = detail.MaxLevel
+					}
+				}
+				if min > max {
+					min = max
+				}
+				return min + rand.Intn(max-min+1)
+			}
+		}
+	}
+	return 2 + rand.Intn(9)
+}
+
+func commandBattle(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("please provide a pokemon name")
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("too many arguments, please provide only one pokemon name")
+	}
+	lead := partyLead(c)
+	if lead == nil {
+		return fmt.Errorf("your party is empty, catch a pokemon first")
+	}
+	wildPokemon, err := fetchPokemon(c, args[1])
+	if err != nil {
+		return err
+	}
+	level := wildLevel(c, wildPokemon.Name)
+	wildStats := calcStats(wildPokemon, level, rollIVs(), nil, rollNature())
+	wild, err := newBattler(c, wildPokemon, level, wildStats, lastMoves(levelUpMoves(wildPokemon, c.VersionGroup, level), 4))
+	if err != nil {
+		return err
+	}
+	player, err := newBattler(c, lead.Pokemon, lead.Level, lead.currentStats(), lead.KnownMoves)
+	if err != nil {
+		return err
+	}
+
+	if encounterShiny(c, wildPokemon.Species.Name) {
+		fmt.Fprintf(c.Out, "A wild shiny %s (Lv. %d) appeared!\n", wild.Name, wild.Level)
+	} else {
+		fmt.Fprintf(c.Out, "A wild %s (Lv. %d) appeared!\n", wild.Name, wild.Level)
+	}
+	fmt.Fprintf(c.Out, "Go, %s!\n", player.Name)
+	for {
+		fmt.Fprintf(c.Out, "%s HP %d/%d | wild %s HP %d/%d\n", player.Name, player.HP, player.Stats["hp"], wild.Name, wild.HP, wild.Stats["hp"])
+		for i, move := range player.Moves {
+			fmt.Fprintf(c.Out, "  %d: %s\n", i+1, move.Name)
+		}
+		answer, ok := prompt(c, fmt.Sprintf("What will %s do? (1-%d, use <potion>, or run): ", player.Name, len(player.Moves)))
+		if !ok || answer == "run" {
+			fmt.Fprintln(c.Out, "Got away safely!")
+			return nil
+		}
+		wildMove := wild.Moves[rand.Intn(len(wild.Moves))]
+
+		if item, found := strings.CutPrefix(answer, "use "); found {
+			healing, ok := potionHealing[item]
+			if !ok {
+				fmt.Fprintf(c.Out, "%s can't be used in battle.\n", item)
+				continue
+			}
+			if !useItem(c, item) {
+				fmt.Fprintf(c.Out, "You don't have any %s.\n", item)
+				continue
+			}
+			fmt.Fprintf(c.Out, "%s recovered %d HP!\n", player.Name, heal(player, healing))
+			if err := useMove(c, globalRNG{}, wild, player, wildMove); err != nil {
+				return err
+			}
+		} else {
+			n, err := strconv.Atoi(answer)
+			if err != nil || n < 1 || n > len(player.Moves) {
+				fmt.Fprintln(c.Out, "Please choose one of the listed moves.")
+				continue
+			}
+			playerMove := player.Moves[n-1]
+
+			first, second := player, wild
+			firstMove, secondMove := playerMove, wildMove
+			if !movesFirst(globalRNG{}, player, wild, playerMove, wildMove) {
+				first, second = wild, player
+				firstMove, secondMove = wildMove, playerMove
+			}
+			if err := useMove(c, globalRNG{}, first, second, firstMove); err != nil {
+				return err
+			}
+			if second.HP > 0 {
+				if err := useMove(c, globalRNG{}, second, first, secondMove); err != nil {
+					return err
+				}
+			}
+		}
+
+		if wild.HP == 0 {
+			fmt.Fprintf(c.Out, "The wild %s fainted!\n", wild.Name)
+			prize := battlePrize * wild.Level
+			earnMoney(c, prize)
+			fmt.Fprintf(c.Out, "You got ₽%d for winning!\n", prize)
+			if item := rollHeldItem(wildPokemon, c.Version); item != "" {
+				addItem(c, item, 1)
+				fmt.Fprintf(c.Out, "The wild %s dropped %s!\n", wild.Name, item)
+			}
+			for stat, gain := range addEffortValues(lead, wildPokemon) {
+				fmt.Fprintf(c.Out, "%s gained %d %s EVs.\n", lead.Name, gain, stat)
+			}
+			return gainExperience(c, lead, experienceYield(wild.BaseExperience, wild.Level))
+		}
+		if player.HP == 0 {
+			fmt.Fprintf(c.Out, "%s fainted! You hurried away from the battle.\n", player.Name)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CaughtPokemon is a pokemon in the trainer's Pokedex together with the
+// progress it has made since it was caught.
+type CaughtPokemon struct {
+	Pokemon
+	CaughtID   int            `json:"caught_id"`
+	Level      int            `json:"level"`
+	Experience int            `json:"experience"`
+	GrowthRate string         `json:"growth_rate"`
+	KnownMoves []string       `json:"known_moves"`
+	Ability    string         `json:"ability"`
+	HeldItem   string         `json:"held_item"`
+	Shiny      bool           `json:"shiny"`
+	CaughtAt   time.Time      `json:"caught_at"`
+	Legendary  bool           `json:"legendary"`
+	Mythical   bool           `json:"mythical"`
+	IVs        map[string]int `json:"ivs"`
+	EVs        map[string]int `json:"evs"`
+	Nature     string         `json:"nature"`
+}
+
+func fetchPokemon(c *config, name string) (Pokemon, error) {
+	pokemon := Pokemon{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", name)
+	if err := fetchJSON(c, url, &pokemon); err != nil {
+		return pokemon, fmt.Errorf("failed to fetch pokemon %s: %v", name, err)
+	}
+	return pokemon, nil
+}
+
+func fetchSpecies(c *config, pokemon Pokemon) (PokemonSpecies, error) {
+	species := PokemonSpecies{}
+	if err := fetchJSON(c, pokemon.Species.URL, &species); err != nil {
+		return species, fmt.Errorf("failed to fetch species %s: %v", pokemon.Species.Name, err)
+	}
+	return species, nil
+}
+
+// newCaughtPokemon builds the caught record for a freshly caught pokemon at
+// the given level, starting it off with the experience for that level and the
+// last four level-up moves it would know in the session's version group.
+func newCaughtPokemon(c *config, pokemon Pokemon, level int) (*CaughtPokemon, error) {
+	species, err := fetchSpecies(c, pokemon)
+	if err != nil {
+		return nil, err
+	}
+	growth, err := fetchGrowthRate(c, species.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &CaughtPokemon{
+		Pokemon:    pokemon,
+		Level:      level,
+		Experience: experienceForLevel(growth, level),
+		GrowthRate: growth.Name,
+		KnownMoves: lastMoves(levelUpMoves(pokemon, c.VersionGroup, level), 4),
+		Ability:    rollAbility(pokemon),
+		HeldItem:   rollHeldItem(pokemon, c.Version),
+		CaughtAt:   time.Now(),
+		Legendary:  species.IsLegendary,
+		Mythical:   species.IsMythical,
+		IVs:        rollIVs(),
+		EVs:        make(map[string]int),
+		Nature:     rollNature(),
+	}, nil
+}
+
+// addCaught puts a newly caught pokemon in the Pokedex under a fresh caught
+// ID and in the party if there is room.
+func addCaught(c *config, pokemon *CaughtPokemon) {
+	c.NextCaughtID++
+	pokemon.CaughtID = c.NextCaughtID
+	c.Pokedex[pokemon.Name] = pokemon
+	addToParty(c, pokemon.Name)
+}
+
+// confirmReplace asks before a pokemon takes the place of the one of the
+// same name already in the Pokedex, which holds one of each. It is false if
+// the trainer says no or can't be asked, so nothing trained is thrown away
+// silently.
+func confirmReplace(c *config, name string) bool {
+	old, ok := c.Pokedex[name]
+	if !ok {
+		return true
+	}
+	answer, ok := prompt(c, fmt.Sprintf("You already have %s #%d (Lv. %d). Release it to make room? (y/n): ", old.Name, old.CaughtID, old.Level))
+	return ok && (answer == "y" || answer == "yes")
+}
+
+// assignCaughtIDs gives caught IDs, in the order they were caught, to
+// pokemon from saves made before there were any.
+func assignCaughtIDs(c *config) {
+	missing := []*CaughtPokemon{}
+	for _, pokemon := range c.Pokedex {
+		c.NextCaughtID = max(c.NextCaughtID, pokemon.CaughtID)
+		if pokemon.CaughtID == 0 {
+			missing = append(missing, pokemon)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if !missing[i].CaughtAt.Equal(missing[j].CaughtAt) {
+			return missing[i].CaughtAt.Before(missing[j].CaughtAt)
+		}
+		return missing[i].Name < missing[j].Name
+	})
+	for _, pokemon := range missing {
+		c.NextCaughtID++
+		pokemon.CaughtID = c.NextCaughtID
+	}
+}
+
+// findCaught looks up a caught pokemon by caught ID or by name.
+func findCaught(c *config, ref string) (*CaughtPokemon, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, pokemon := range c.Pokedex {
+			if pokemon.CaughtID == id {
+				return pokemon, nil
+			}
+		}
+		return nil, fmt.Errorf("no caught pokemon has ID %d", id)
+	}
+	if pokemon, ok := c.Pokedex[ref]; ok {
+		return pokemon, nil
+	}
+	return nil, fmt.Errorf("pokemon not found in your Pokedex")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glitchdawg/pokedex/internal/termimg"
+)
+
+const (
+	compareBarWidth = 10
+	// maxBaseStat is the highest base stat any pokemon has, which bars are
+	// scaled against.
+	maxBaseStat   = 255
+	compareColumn = 22
+)
+
+func statBar(value int) string {
+	filled := value * compareBarWidth / maxBaseStat
+	if filled > compareBarWidth {
+		filled = compareBarWidth
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", compareBarWidth-filled)
+}
+
+// highlight marks the winner of a row with a star, in colour when the
+// terminal allows it.
+func highlight(c *config, s string) string {
+	if graphicsMode(c) == termimg.ModeText {
+		return s + "*"
+	}
+	return "\x1b[1;32m" + s + "*\x1b[0m"
+}
+
+// pad left-aligns s in a column, ignoring any colour codes in it.
+func pad(s string, visible int) string {
+	if visible >= compareColumn {
+		return s + " "
+	}
+	return s + strings.Repeat(" ", compareColumn-visible)
+}
+
+func typeNames(pokemon Pokemon) []string {
+	names := []string{}
+	for _, t := range pokemon.Types {
+		names = append(names, t.Type.Name)
+	}
+	return names
+}
+
+func abilityNames(pokemon Pokemon) []string {
+	names := []string{}
+	for _, a := range pokemon.Abilities {
+		names = append(names, a.Ability.Name)
+	}
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyIn lists the entries of list that none of the others have.
+func onlyIn(list []string, others [][]string) []string {
+	unique := []string{}
+	for _, item := range list {
+		shared := false
+		for _, other := range others {
+			if contains(other, item) {
+				shared = true
+			}
+		}
+		if !shared {
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
+
+func commandCompare(c *config, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("please provide at least two pokemon to compare")
+	}
+	pokemon := []Pokemon{}
+	for _, name := range args[1:] {
+		if caught, ok := c.Pokedex[name]; ok {
+			pokemon = append(pokemon, caught.Pokemon)
+			continue
+		}
+		p, err := fetchPokemon(c, name)
+		if err != nil {
+			return err
+		}
+		pokemon = append(pokemon, p)
+	}
+
+	fmt.Fprint(c.Out, pad("", 0))
+	for _, p := range pokemon {
+		fmt.Fprint(c.Out, pad(p.Name, len(p.Name)))
+	}
+	fmt.Fprintln(c.Out)
+
+	row := func(label string, values []int, bars bool) {
+		best := 0
+		for _, v := range values {
+			best = max(best, v)
+		}
+		winners := 0
+		for _, v := range values {
+			if v == best {
+				winners++
+			}
+		}
+		fmt.Fprint(c.Out, pad(label, len(label)))
+		for _, v := range values {
+			cell := fmt.Sprintf("%3d ", v)
+			if v == best && winners < len(values) {
+				cell = highlight(c, fmt.Sprintf("%3d", v))
+			}
+			visible := 4
+			if bars {
+				cell += statBar(v)
+				visible += compareBarWidth
+			}
+			fmt.Fprint(c.Out, pad(cell, visible))
+		}
+		fmt.Fprintln(c.Out)
+	}
+
+	totals := make([]int, len(pokemon))
+	for _, stat := range statNames {
+		values := []int{}
+		for i, p := range pokemon {
+			v := baseStat(p, stat)
+			values = append(values, v)
+			totals[i] += v
+		}
+		row(stat, values, true)
+	}
+	row("total", totals, false)
+
+	fmt.Fprint(c.Out, pad("types", 5))
+	for _, p := range pokemon {
+		types := strings.Join(typeNames(p), "/")
+		fmt.Fprint(c.Out, pad(types, len(types)))
+	}
+	fmt.Fprintln(c.Out)
+
+	fmt.Fprintln(c.Out, "Differences:")
+	for i, p := range pokemon {
+		otherTypes, otherAbilities := [][]string{}, [][]string{}
+		for j, other := range pokemon {
+			if i != j {
+				otherTypes = append(otherTypes, typeNames(other))
+				otherAbilities = append(otherAbilities, abilityNames(other))
+			}
+		}
+		types := onlyIn(typeNames(p), otherTypes)
+		abilities := onlyIn(abilityNames(p), otherAbilities)
+		if len(types) == 0 && len(abilities) == 0 {
+			fmt.Fprintf(c.Out, "  %s: nothing unique\n", p.Name)
+			continue
+		}
+		parts := []string{}
+		if len(types) > 0 {
+			parts = append(parts, "types "+strings.Join(types, ", "))
+		}
+		if len(abilities) > 0 {
+			parts = append(parts, "abilities "+strings.Join(abilities, ", "))
+		}
+		fmt.Fprintf(c.Out, "  only %s has %s\n", p.Name, strings.Join(parts, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// typeChart holds the damage multiplier of every attacking type against
+// every single defending type.
+type typeChart struct {
+	types      []string
+	multiplier map[string]map[string]float64
+}
+
+// fetchTypeChart builds the chart from /type, leaving out types no pokemon
+// has such as shadow.
+func fetchTypeChart(c *config) (typeChart, error) {
+	chart := typeChart{multiplier: make(map[string]map[string]float64)}
+	list, err := fetchResourceList(c, "type")
+	if err != nil {
+		return chart, err
+	}
+	for _, result := range list.Results {
+		t, err := fetchType(c, result.Name)
+		if err != nil {
+			return chart, err
+		}
+		if len(t.Pokemon) == 0 {
+			continue
+		}
+		chart.types = append(chart.types, t.Name)
+		row := make(map[string]float64)
+		for _, d := range t.DamageRelations.DoubleDamageTo {
+			row[d.Name] = 2
+		}
+		for _, d := range t.DamageRelations.HalfDamageTo {
+			row[d.Name] = 0.5
+		}
+		for _, d := range t.DamageRelations.NoDamageTo {
+			row[d.Name] = 0
+		}
+		chart.multiplier[t.Name] = row
+	}
+	return chart, nil
+}
+
+// against returns the multiplier of an attacking type against a pokemon
+// with the given types.
+func (chart typeChart) against(attack string, defend []string) float64 {
+	m := 1.0
+	for _, d := range defend {
+		if v, ok := chart.multiplier[attack][d]; ok {
+			m *= v
+		}
+	}
+	return m
+}
+
+// damagingMoveTypes returns the types of the damaging moves a caught pokemon
+// knows.
+func damagingMoveTypes(c *config, pokemon *CaughtPokemon) ([]string, error) {
+	types := []string{}
+	for _, name := range pokemon.KnownMoves {
+		move, err := fetchMove(c, name)
+		if err != nil {
+			return nil, err
+		}
+		if move.Power > 0 && !contains(types, move.Type.Name) {
+			types = append(types, move.Type.Name)
+		}
+	}
+	return types, nil
+}
+
+func multiplierCell(m float64) string {
+	switch m {
+	case 0:
+		return "0"
+	case 0.25:
+		return "¼"
+	case 0.5:
+		return "½"
+	case 1:
+		return "·"
+	}
+	return fmt.Sprintf("%gx", m)
+}
+
+// partyMembers returns the caught pokemon named in refs, or the party when
+// none are named.
+func partyMembers(c *config, refs []string) ([]*CaughtPokemon, error) {
+	if len(refs) == 0 {
+		refs = c.Party
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("your party is empty, name some caught pokemon instead")
+	}
+	members := []*CaughtPokemon{}
+	for _, ref := range refs {
+		pokemon, err := findCaught(c, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ref, err)
+		}
+		members = append(members, pokemon)
+	}
+	return members, nil
+}
+
+func commandCoverage(c *config, args []string) error {
+	members, err := partyMembers(c, args[1:])
+	if err != nil {
+		return err
+	}
+	chart, err := fetchTypeChart(c)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.Out, "Defense (damage taken from each attacking type):")
+	fmt.Fprintf(c.Out, "%-10s", "")
+	for _, m := range members {
+		name := m.Name
+		if len(name) > 8 {
+			name = name[:8]
+		}
+		fmt.Fprintf(c.Out, "%-9s", name)
+	}
+	fmt.Fprintf(c.Out, "%-6s%s\n", "weak", "resist")
+	holes := []string{}
+	for _, attack := range chart.types {
+		fmt.Fprintf(c.Out, "%-10s", attack)
+		weak, resist := 0, 0
+		for _, m := range members {
+			multiplier := chart.against(attack, typeNames(m.Pokemon))
+			if multiplier > 1 {
+				weak++
+			} else if multiplier < 1 {
+				resist++
+			}
+			cell := multiplierCell(multiplier)
+			fmt.Fprint(c.Out, cell+strings.Repeat(" ", 9-len([]rune(cell))))
+		}
+		fmt.Fprintf(c.Out, "%-6d%d\n", weak, resist)
+		if weak > resist {
+			holes = append(holes, attack)
+		}
+	}
+
+	moveTypes := []string{}
+	for _, m := range members {
+		types, err := damagingMoveTypes(c, m)
+		if err != nil {
+			return err
+		}
+		for _, t := range types {
+			if !contains(moveTypes, t) {
+				moveTypes = append(moveTypes, t)
+			}
+		}
+	}
+	covered, uncovered := []string{}, []string{}
+	for _, defend := range chart.types {
+		best := 0.0
+		for _, attack := range moveTypes {
+			best = max(best, chart.against(attack, []string{defend}))
+		}
+		if best > 1 {
+			covered = append(covered, defend)
+		} else {
+			uncovered = append(uncovered, defend)
+		}
+	}
+
+	fmt.Fprintln(c.Out)
+	if len(holes) > 0 {
+		fmt.Fprintf(c.Out, "More of the team is weak to than resists: %s\n", strings.Join(holes, ", "))
+	}
+	fmt.Fprintf(c.Out, "Damaging move types: %s\n", orNone(strings.Join(moveTypes, ", ")))
+	fmt.Fprintf(c.Out, "Hit super effectively: %s\n", orNone(strings.Join(covered, ", ")))
+	fmt.Fprintf(c.Out, "Uncovered types: %s\n", orNone(strings.Join(uncovered, ", ")))
+	return nil
+}
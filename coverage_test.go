@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+// testChart is a slice of the real type chart covering a few types.
+func testChart() typeChart {
+	return typeChart{
+		types: []string{"normal", "fire", "water", "grass", "electric", "ground", "flying", "rock"},
+		multiplier: map[string]map[string]float64{
+			"normal":   {"rock": 0.5},
+			"fire":     {"fire": 0.5, "water": 0.5, "grass": 2, "rock": 0.5},
+			"water":    {"fire": 2, "water": 0.5, "grass": 0.5, "ground": 2, "rock": 2},
+			"grass":    {"fire": 0.5, "water": 2, "grass": 0.5, "ground": 2, "flying": 0.5, "rock": 2},
+			"electric": {"water": 2, "grass": 0.5, "electric": 0.5, "ground": 0, "flying": 2},
+			"ground":   {"fire": 2, "grass": 0.5, "electric": 2, "flying": 0, "rock": 2},
+			"flying":   {"grass": 2, "electric": 0.5, "rock": 0.5},
+			"rock":     {"fire": 2, "ground": 0.5, "flying": 2},
+		},
+	}
+}
+
+func TestTypeChartAgainst(t *testing.T) {
+	chart := testChart()
+	cases := []struct {
+		attack   string
+		defend   []string
+		expected float64
+	}{
+		{attack: "water", defend: []string{"ground", "rock"}, expected: 4},
+		{attack: "electric", defend: []string{"water", "flying"}, expected: 4},
+		{attack: "electric", defend: []string{"ground", "flying"}, expected: 0},
+		{attack: "grass", defend: []string{"fire", "flying"}, expected: 0.25},
+		{attack: "normal", defend: []string{"fire"}, expected: 1},
+	}
+	for _, c := range cases {
+		if actual := chart.against(c.attack, c.defend); actual != c.expected {
+			t.Errorf("%s against %v: expected %g, got %g", c.attack, c.defend, c.expected, actual)
+		}
+	}
+	if multiplierCell(0.5) != "½" || multiplierCell(4) != "4x" || multiplierCell(1) != "·" {
+		t.Error("unexpected multiplier cells")
+	}
+}
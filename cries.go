@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// saveCry downloads a pokemon's cry into the data directory, unless it is
+// already there, and returns the path of the file.
+func saveCry(c *config, pokemon Pokemon, legacy bool) (string, error) {
+	url := pokemon.Cries.Latest
+	name := pokemon.Name + ".ogg"
+	if legacy {
+		url = pokemon.Cries.Legacy
+		name = pokemon.Name + "-legacy.ogg"
+	}
+	if url == "" {
+		return "", fmt.Errorf("%s has no cry", pokemon.Name)
+	}
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "cries", name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to check for cry: %v", err)
+	}
+	data, err := fetchBytes(c, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch cry: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cries directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write cry: %v", err)
+	}
+	return path, nil
+}
+
+// playCry plays a pokemon's cry with the configured player command, or prints
+// where the file is when there is none.
+func playCry(c *config, pokemon Pokemon, legacy bool) error {
+	path, err := saveCry(c, pokemon, legacy)
+	if err != nil {
+		return err
+	}
+	player := strings.Fields(c.Settings.CryPlayer)
+	if len(player) == 0 {
+		fmt.Fprintln(c.Out, path)
+		return nil
+	}
+	cmd := exec.Command(player[0], append(player[1:], path)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to play cry with %s: %v", player[0], err)
+	}
+	return nil
+}
+
+func commandCry(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("please provide a pokemon name")
+	}
+	legacy := len(args) == 3 && args[2] == "--legacy"
+	if len(args) > 2 && !legacy {
+		return fmt.Errorf("usage: cry <name> [--legacy]")
+	}
+	pokemon, err := fetchPokemon(c, args[1])
+	if err != nil {
+		return err
+	}
+	return playCry(c, pokemon, legacy)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+)
+
+// locationPageSize matches PokeAPI's default page size, so the dashboard's
+// pages share cache entries with map and mapb.
+const locationPageSize = 20
+
+//go:embed web
+var webFiles embed.FS
+
+// dashboardHandler serves the single-page dashboard built into the binary.
+func dashboardHandler() http.Handler {
+	files, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(files)
+}
+
+// locationsURL is the PokeAPI URL for the page of location areas starting
+// at offset, spelled the way map and mapb spell it.
+func locationsURL(offset int) string {
+	if offset == 0 {
+		return "https://pokeapi.co/api/v2/location-area/"
+	}
+	return fmt.Sprintf("https://pokeapi.co/api/v2/location-area/?offset=%d&limit=%d", offset, locationPageSize)
+}
+
+// handleLocations pages through location areas like map and mapb, without
+// moving the REPL's own page.
+func (s *server) handleLocations(w http.ResponseWriter, r *http.Request) {
+	offset := 0
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("offset must be a non-negative number, got %q", raw))
+			return
+		}
+		offset = n
+	}
+	locations, err := fetchLocations(locationsURL(offset), s.c)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to fetch locations: %v", err))
+		return
+	}
+	areas := []string{}
+	for _, location := range locations.Results {
+		areas = append(areas, location.Name)
+	}
+	response := map[string]interface{}{
+		"areas":    areas,
+		"offset":   offset,
+		"count":    locations.Count,
+		"next":     nil,
+		"previous": nil,
+	}
+	if locations.Next != "" {
+		response["next"] = offset + locationPageSize
+	}
+	if offset > 0 {
+		response["previous"] = max(offset-locationPageSize, 0)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
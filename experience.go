@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const maxLevel = 100
+
+func fetchGrowthRate(c *config, name string) (GrowthRate, error) {
+	growth := GrowthRate{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/growth-rate/%s", name)
+	if err := fetchJSON(c, url, &growth); err != nil {
+		return growth, fmt.Errorf("failed to fetch growth rate %s: %v", name, err)
+	}
+	return growth, nil
+}
+
+// experienceForLevel returns the total experience needed to reach level on
+// the given growth curve.
+func experienceForLevel(growth GrowthRate, level int) int {
+	for _, l := range growth.Levels {
+		if l.Level == level {
+			return l.Experience
+		}
+	}
+	return 0
+}
+
+// levelForExperience returns the level reached with the given total experience.
+func levelForExperience(growth GrowthRate, experience int) int {
+	level := 1
+	for _, l := range growth.Levels {
+		if l.Experience <= experience && l.Level > level {
+			level = l.Level
+		}
+	}
+	return level
+}
+
+// experienceYield is the experience awarded for defeating or catching a
+// pokemon with the given base experience at the given level.
+func experienceYield(baseExperience, level int) int {
+	yield := baseExperience * level / 7
+	if yield < 1 {
+		yield = 1
+	}
+	return yield
+}
+
+type learnableMove struct {
+	name  string
+	level int
+}
+
+func levelUpLearnset(pokemon Pokemon, versionGroup string) []learnableMove {
+	learnset := []learnableMove{}
+	for _, move := range pokemon.Moves {
+		for _, detail := range move.VersionGroupDetails {
+			if detail.VersionGroup.Name != versionGroup || detail.MoveLearnMethod.Name != "level-up" {
+				continue
+			}
+			learnset = append(learnset, learnableMove{name: move.Move.Name, level: detail.LevelLearnedAt})
+		}
+	}
+	sort.SliceStable(learnset, func(i, j int) bool {
+		return learnset[i].level < learnset[j].level
+	})
+	return learnset
+}
+
+// levelUpMoves lists, in the order they are learned, the moves a pokemon
+// learns by leveling up to level in the given version group.
+func levelUpMoves(pokemon Pokemon, versionGroup string, level int) []string {
+	moves := []string{}
+	for _, move := range levelUpLearnset(pokemon, versionGroup) {
+		if move.level <= level {
+			moves = append(moves, move.name)
+		}
+	}
+	return moves
+}
+
+// movesLearnedAt lists the moves a pokemon learns upon reaching exactly level.
+func movesLearnedAt(pokemon Pokemon, versionGroup string, level int) []string {
+	moves := []string{}
+	for _, move := range levelUpLearnset(pokemon, versionGroup) {
+		if move.level == level {
+			moves = append(moves, move.name)
+		}
+	}
+	return moves
+}
+
+func lastMoves(moves []string, n int) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for i := len(moves) - 1; i >= 0 && len(unique) < n; i-- {
+		if !seen[moves[i]] {
+			seen[moves[i]] = true
+			unique = append([]string{moves[i]}, unique...)
+		}
+	}
+	return unique
+}
+
+// gainExperience awards experience to a caught pokemon, leveling it up as
+// many times as its growth curve allows and offering any moves it learns on
+// the way.
+func gainExperience(c *config, pokemon *CaughtPokemon, amount int) error {
+	if pokemon.Level >= maxLevel {
+		return nil
+	}
+	growth, err := fetchGrowthRate(c, pokemon.GrowthRate)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "%s gained %d Exp. Points!\n", pokemon.Name, amount)
+	pokemon.Experience += amount
+	if max := experienceForLevel(growth, maxLevel); pokemon.Experience > max {
+		pokemon.Experience = max
+	}
+	for pokemon.Level < levelForExperience(growth, pokemon.Experience) {
+		before := pokemon.currentStats()
+		pokemon.Level++
+		after := pokemon.currentStats()
+		fmt.Fprintf(c.Out, "%s grew to level %d!\n", pokemon.Name, pokemon.Level)
+		for _, stat := range pokemon.Stats {
+			name := stat.Stat.Name
+			fmt.Fprintf(c.Out, "  %s: %d (+%d)\n", name, after[name], after[name]-before[name])
+		}
+		for _, move := range movesLearnedAt(pokemon.Pokemon, c.VersionGroup, pokemon.Level) {
+			learnMove(c, pokemon, move)
+		}
+	}
+	return nil
+}
+
+// learnMove teaches a move to a caught pokemon, asking which move to forget
+// when it already knows four.
+func learnMove(c *config, pokemon *CaughtPokemon, move string) {
+	for _, known := range pokemon.KnownMoves {
+		if known == move {
+			return
+		}
+	}
+	if len(pokemon.KnownMoves) < 4 {
+		pokemon.KnownMoves = append(pokemon.KnownMoves, move)
+		fmt.Fprintf(c.Out, "%s learned %s!\n", pokemon.Name, move)
+		return
+	}
+	fmt.Fprintf(c.Out, "%s wants to learn %s, but it already knows 4 moves:\n", pokemon.Name, move)
+	for i, known := range pokemon.KnownMoves {
+		fmt.Fprintf(c.Out, "  %d: %s\n", i+1, known)
+	}
+	answer, _ := prompt(c, "Forget which move? (1-4, or skip): ")
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(pokemon.KnownMoves) {
+		fmt.Fprintf(c.Out, "%s did not learn %s.\n", pokemon.Name, move)
+		return
+	}
+	fmt.Fprintf(c.Out, "1, 2 and... Poof! %s forgot %s and learned %s!\n", pokemon.Name, pokemon.KnownMoves[n-1], move)
+	pokemon.KnownMoves[n-1] = move
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLevelForExperience(t *testing.T) {
+	growth := GrowthRate{}
+	err := json.Unmarshal([]byte(`{"name":"medium","levels":[
+		{"level":1,"experience":0},
+		{"level":2,"experience":8},
+		{"level":3,"experience":27},
+		{"level":4,"experience":64}]}`), &growth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		experience int
+		expected   int
+	}{
+		{experience: 0, expected: 1},
+		{experience: 7, expected: 1},
+		{experience: 8, expected: 2},
+		{experience: 63, expected: 3},
+		{experience: 1000, expected: 4},
+	}
+	for _, c := range cases {
+		if actual := levelForExperience(growth, c.experience); actual != c.expected {
+			t.Errorf("expected level %d for %d exp, got %d", c.expected, c.experience, actual)
+		}
+	}
+	if actual := experienceForLevel(growth, 3); actual != 27 {
+		t.Errorf("expected 27 exp for level 3, got %d", actual)
+	}
+}
+
+func TestLevelUpMoves(t *testing.T) {
+	pokemon := Pokemon{}
+	err := json.Unmarshal([]byte(`{"moves":[
+		{"move":{"name":"thunder-shock"},"version_group_details":[
+			{"level_learned_at":1,"move_learn_method":{"name":"level-up"},"version_group":{"name":"diamond-pearl"}}]},
+		{"move":{"name":"thunderbolt"},"version_group_details":[
+			{"level_learned_at":0,"move_learn_method":{"name":"machine"},"version_group":{"name":"diamond-pearl"}}]},
+		{"move":{"name":"quick-attack"},"version_group_details":[
+			{"level_learned_at":13,"move_learn_method":{"name":"level-up"},"version_group":{"name":"diamond-pearl"}},
+			{"level_learned_at":16,"move_learn_method":{"name":"level-up"},"version_group":{"name":"red-blue"}}]},
+		{"move":{"name":"growl"},"version_group_details":[
+			{"level_learned_at":1,"move_learn_method":{"name":"level-up"},"version_group":{"name":"diamond-pearl"}}]}]}`), &pokemon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := levelUpMoves(pokemon, "diamond-pearl", 10)
+	if len(moves) != 2 || moves[0] != "thunder-shock" || moves[1] != "growl" {
+		t.Errorf("unexpected moves at level 10: %v", moves)
+	}
+	learned := movesLearnedAt(pokemon, "diamond-pearl", 13)
+	if len(learned) != 1 || learned[0] != "quick-attack" {
+		t.Errorf("unexpected moves learned at 13: %v", learned)
+	}
+	if learned := movesLearnedAt(pokemon, "red-blue", 13); len(learned) != 0 {
+		t.Errorf("expected no red-blue moves at 13, got %v", learned)
+	}
+	last := lastMoves([]string{"a", "b", "c", "d", "e", "b"}, 4)
+	if len(last) != 4 || last[0] != "c" || last[3] != "b" {
+		t.Errorf("unexpected last moves: %v", last)
+	}
+}
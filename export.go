@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var exportFormats = []string{"csv", "md", "html"}
+
+// exportRecord is one caught pokemon flattened for a report.
+type exportRecord struct {
+	CaughtID  int
+	Name      string
+	Number    int
+	Level     int
+	Shiny     bool
+	Nature    string
+	Ability   string
+	HeldItem  string
+	Types     []string
+	Abilities []string
+	Stats     []int
+	Total     int
+	// Sprite is a data URI, only filled in for HTML reports.
+	Sprite template.URL
+}
+
+func exportRecords(pokedex map[string]*CaughtPokemon) []exportRecord {
+	records := []exportRecord{}
+	for _, pokemon := range pokedex {
+		stats := pokemon.currentStats()
+		record := exportRecord{
+			CaughtID:  pokemon.CaughtID,
+			Name:      pokemon.Name,
+			Number:    pokemon.ID,
+			Level:     pokemon.Level,
+			Shiny:     pokemon.Shiny,
+			Nature:    pokemon.Nature,
+			Ability:   pokemon.Ability,
+			HeldItem:  pokemon.HeldItem,
+			Types:     typeNames(pokemon.Pokemon),
+			Abilities: abilityNames(pokemon.Pokemon),
+		}
+		for _, stat := range statNames {
+			record.Stats = append(record.Stats, stats[stat])
+			record.Total += stats[stat]
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CaughtID < records[j].CaughtID })
+	return records
+}
+
+func exportHeader() []string {
+	return append([]string{"caught id", "name", "number", "level", "shiny", "nature", "ability", "held item", "types", "possible abilities"}, append(statNames, "total")...)
+}
+
+func (r exportRecord) fields() []string {
+	fields := []string{
+		strconv.Itoa(r.CaughtID), r.Name, strconv.Itoa(r.Number), strconv.Itoa(r.Level), strconv.FormatBool(r.Shiny),
+		r.Nature, r.Ability, r.HeldItem, strings.Join(r.Types, "/"), strings.Join(r.Abilities, "/"),
+	}
+	for _, stat := range r.Stats {
+		fields = append(fields, strconv.Itoa(stat))
+	}
+	return append(fields, strconv.Itoa(r.Total))
+}
+
+func writeCSV(w io.Writer, records []exportRecord) error {
+	out := csv.NewWriter(w)
+	out.Write(exportHeader())
+	for _, record := range records {
+		out.Write(record.fields())
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func markdownRow(fields []string) string {
+	for i, field := range fields {
+		fields[i] = strings.ReplaceAll(field, "|", `\|`)
+	}
+	return "| " + strings.Join(fields, " | ") + " |\n"
+}
+
+func writeMarkdown(w io.Writer, records []exportRecord) error {
+	header := exportHeader()
+	rule := make([]string, len(header))
+	for i := range rule {
+		rule[i] = "---"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Pokedex\n\n%d pokemon caught.\n\n", len(records))
+	b.WriteString(markdownRow(header))
+	b.WriteString(markdownRow(rule))
+	for _, record := range records {
+		b.WriteString(markdownRow(record.fields()))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Pokedex</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #eee; }
+td.number { text-align: right; }
+img { image-rendering: pixelated; width: 64px; height: 64px; }
+</style>
+</head>
+<body>
+<h1>Pokedex</h1>
+<p>{{len .Records}} pokemon caught.</p>
+<table>
+<tr><th></th>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Records}}<tr>
+<td>{{if .Sprite}}<img src="{{.Sprite}}" alt="{{.Name}}">{{end}}</td>
+<td class="number">{{.CaughtID}}</td><td>{{.Name}}{{if .Shiny}} ★{{end}}</td><td class="number">#{{printf "%03d" .Number}}</td><td class="number">{{.Level}}</td><td>{{.Shiny}}</td>
+<td>{{.Nature}}</td><td>{{.Ability}}</td><td>{{.HeldItem}}</td>
+<td>{{range $i, $t := .Types}}{{if $i}}/{{end}}{{$t}}{{end}}</td><td>{{range $i, $a := .Abilities}}{{if $i}}/{{end}}{{$a}}{{end}}</td>
+{{range .Stats}}<td class="number">{{.}}</td>{{end}}<td class="number">{{.Total}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, records []exportRecord) error {
+	return htmlReport.Execute(w, struct {
+		Header  []string
+		Records []exportRecord
+	}{exportHeader(), records})
+}
+
+// spriteDataURI embeds a sprite in a page so the report works offline.
+func spriteDataURI(data []byte) template.URL {
+	return template.URL("data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+func commandExport(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: export <file> [--format csv|md|html]")
+	}
+	path := args[1]
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for i := 2; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "--format":
+			if i+1 == len(args) {
+				return fmt.Errorf("--format needs one of %s", strings.Join(exportFormats, ", "))
+			}
+			format = strings.ToLower(args[i+1])
+			i++
+		default:
+			return fmt.Errorf("unknown option: %s", args[i])
+		}
+	}
+	if format == "markdown" {
+		format = "md"
+	}
+	if !contains(exportFormats, format) {
+		return fmt.Errorf("unknown format %q, choose one of %s", format, strings.Join(exportFormats, ", "))
+	}
+
+	records := exportRecords(c.Pokedex)
+	if format == "html" {
+		for i, record := range records {
+			pokemon := c.Pokedex[record.Name]
+			url, err := spriteURL(pokemon.Pokemon, pokemon.Shiny, false, 0)
+			if err != nil {
+				continue
+			}
+			data, err := fetchBytes(c, url)
+			if err != nil {
+				fmt.Fprintf(c.Out, "Skipping the sprite of %s: %v\n", record.Name, err)
+				continue
+			}
+			records[i].Sprite = spriteDataURI(data)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	switch format {
+	case "csv":
+		err = writeCSV(file, records)
+	case "md":
+		err = writeMarkdown(file, records)
+	case "html":
+		err = writeHTML(file, records)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	fmt.Fprintf(c.Out, "Exported %d pokemon to %s\n", len(records), path)
+	return nil
+}
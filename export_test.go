@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func testExportRecords(t *testing.T) []exportRecord {
+	pokedex := testPokedex(t)
+	for i, name := range []string{"arcanine", "squirtle", "charizard", "charmander"} {
+		pokedex[name].CaughtID = i + 1
+	}
+	pokedex["squirtle"].Ability = "rain-dish"
+	pokedex["charizard"].Shiny = true
+	return exportRecords(pokedex)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b strings.Builder
+	if err := writeCSV(&b, testExportRecords(t)); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || len(rows[0]) != len(exportHeader()) {
+		t.Fatalf("expected a header and 4 rows of %d fields, got %v", len(exportHeader()), rows)
+	}
+	if got := strings.Join(rows[2][:7], ","); got != "2,squirtle,7,5,false,,rain-dish" {
+		t.Errorf("unexpected squirtle row: %s", got)
+	}
+	if got := rows[3][8]; got != "fire/flying" {
+		t.Errorf("expected charizard's types to be fire/flying, got %s", got)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	records := testExportRecords(t)
+	records[0].HeldItem = "a|b"
+	var b strings.Builder
+	if err := writeMarkdown(&b, records); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 10 || !strings.HasPrefix(lines[5], "| ---") {
+		t.Fatalf("unexpected table:\n%s", b.String())
+	}
+	if !strings.Contains(lines[6], `a\|b`) {
+		t.Errorf("expected the pipe in %q to be escaped", lines[6])
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	records := testExportRecords(t)
+	records[0].Name = "<arcanine>"
+	records[1].Sprite = spriteDataURI([]byte("\x89PNG\r\n\x1a\n"))
+	var b strings.Builder
+	if err := writeHTML(&b, records); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	if !strings.Contains(page, `src="data:image/png;base64,iVBORw0KGgo="`) {
+		t.Errorf("expected the sprite to be embedded as a data URI:\n%s", page)
+	}
+	if strings.Contains(page, "<arcanine>") || !strings.Contains(page, "&lt;arcanine&gt;") {
+		t.Errorf("expected names to be escaped")
+	}
+	if strings.Count(page, "<img") != 1 {
+		t.Errorf("expected only records with sprites to have an image")
+	}
+}
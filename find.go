@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/glitchdawg/pokedex/internal/query"
+)
+
+// findSchema is every field the find command understands.
+var findSchema = query.Schema{
+	"name":           query.Text,
+	"type":           query.Text,
+	"ability":        query.Text,
+	"held-item":      query.Text,
+	"nature":         query.Text,
+	"move":           query.Text,
+	"id":             query.Number,
+	"level":          query.Number,
+	"experience":     query.Number,
+	"height":         query.Number,
+	"weight":         query.Number,
+	"total":          query.Number,
+	"shiny":          query.Flag,
+	"legendary":      query.Flag,
+	"mythical":       query.Flag,
+	"hidden-ability": query.Flag,
+}
+
+func init() {
+	for _, stat := range statNames {
+		findSchema[stat] = query.Number
+	}
+}
+
+// caughtRecord lets queries look at a caught pokemon.
+type caughtRecord struct {
+	*CaughtPokemon
+}
+
+func (r caughtRecord) Number(field string) int {
+	switch field {
+	case "id":
+		return r.ID
+	case "level":
+		return r.Level
+	case "experience":
+		return r.Experience
+	case "height":
+		return r.Height
+	case "weight":
+		return r.Weight
+	case "total":
+		total := 0
+		for _, stat := range r.Stats {
+			total += stat.BaseStat
+		}
+		return total
+	}
+	return baseStat(r.Pokemon, field)
+}
+
+func (r caughtRecord) Text(field string) []string {
+	values := []string{}
+	switch field {
+	case "name":
+		values = append(values, r.Name)
+	case "type":
+		for _, t := range r.Types {
+			values = append(values, t.Type.Name)
+		}
+	case "ability":
+		for _, a := range r.Abilities {
+			values = append(values, a.Ability.Name)
+		}
+	case "held-item":
+		values = append(values, r.HeldItem)
+	case "nature":
+		values = append(values, r.Nature)
+	case "move":
+		values = append(values, r.KnownMoves...)
+	}
+	return values
+}
+
+func (r caughtRecord) Flag(field string) bool {
+	switch field {
+	case "shiny":
+		return r.Shiny
+	case "legendary":
+		return r.Legendary
+	case "mythical":
+		return r.Mythical
+	case "hidden-ability":
+		for _, a := range r.Abilities {
+			if a.IsHidden && a.Ability.Name == r.Ability {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findPokemon runs a query over the Pokedex, ordering by national dex number
+// unless the query asks for something else.
+func findPokemon(pokedex map[string]*CaughtPokemon, q *query.Query) []*CaughtPokemon {
+	results := []*CaughtPokemon{}
+	for _, pokemon := range pokedex {
+		if q.Match(caughtRecord{pokemon}) {
+			results = append(results, pokemon)
+		}
+	}
+	less := func(a, b *CaughtPokemon) bool {
+		ra, rb := caughtRecord{a}, caughtRecord{b}
+		if kind, ok := findSchema[q.OrderBy]; ok && kind == query.Number {
+			if na, nb := ra.Number(q.OrderBy), rb.Number(q.OrderBy); na != nb {
+				return na < nb
+			}
+		} else if ok && kind == query.Text {
+			ta, tb := strings.Join(ra.Text(q.OrderBy), ","), strings.Join(rb.Text(q.OrderBy), ",")
+			if ta != tb {
+				return ta < tb
+			}
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Name < b.Name
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if q.Desc {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+func commandFind(c *config, args []string) error {
+	q, err := query.Parse(strings.Join(args[1:], " "), findSchema)
+	if err != nil {
+		return err
+	}
+	results := findPokemon(c.Pokedex, q)
+	if len(results) == 0 {
+		fmt.Fprintln(c.Out, "No pokemon in your Pokedex match.")
+		return nil
+	}
+	for _, pokemon := range results {
+		line := fmt.Sprintf("- #%03d %s (Lv. %d)", pokemon.ID, pokemon.Name, pokemon.Level)
+		if q.OrderBy != "" && q.OrderBy != "name" && q.OrderBy != "id" && q.OrderBy != "level" {
+			r := caughtRecord{pokemon}
+			if findSchema[q.OrderBy] == query.Number {
+				line += fmt.Sprintf(" %s=%d", q.OrderBy, r.Number(q.OrderBy))
+			} else {
+				line += fmt.Sprintf(" %s=%s", q.OrderBy, strings.Join(r.Text(q.OrderBy), ","))
+			}
+		}
+		fmt.Fprintln(c.Out, line)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/glitchdawg/pokedex/internal/query"
+)
+
+func TestFindPokemon(t *testing.T) {
+	pokedex := testPokedex(t)
+	pokedex["arcanine"].Legendary = true
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "type=fire and speed>90 and not legendary", expected: []string{"charizard"}},
+		{query: "type=fire order by attack desc", expected: []string{"arcanine", "charizard", "charmander"}},
+		{query: "total>=150 order by name limit 2", expected: []string{"arcanine", "charizard"}},
+		{query: "type=water or legendary", expected: []string{"squirtle", "arcanine"}},
+	}
+	for _, c := range cases {
+		q, err := query.Parse(c.query, findSchema)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.query, err)
+		}
+		actual := names(findPokemon(pokedex, q))
+		if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.query, c.expected, actual)
+		}
+	}
+}
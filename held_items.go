@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// heldItemRarities returns each item a pokemon can hold in the wild in the
+// given version, with its rarity as a percentage.
+func heldItemRarities(pokemon Pokemon, version string) map[string]int {
+	rarities := make(map[string]int)
+	for _, held := range pokemon.HeldItems {
+		for _, detail := range held.VersionDetails {
+			if detail.Version.Name == version {
+				rarities[held.Item.Name] = detail.Rarity
+			}
+		}
+	}
+	return rarities
+}
+
+// rollHeldItem decides which item, if any, a wild pokemon is holding.
+func rollHeldItem(pokemon Pokemon, version string) string {
+	roll := rand.Intn(100)
+	for _, held := range pokemon.HeldItems {
+		for _, detail := range held.VersionDetails {
+			if detail.Version.Name != version {
+				continue
+			}
+			if roll < detail.Rarity {
+				return held.Item.Name
+			}
+			roll -= detail.Rarity
+		}
+	}
+	return ""
+}
+
+func printHeldItems(c *config, pokemon *CaughtPokemon) {
+	if pokemon.HeldItem != "" {
+		fmt.Fprintf(c.Out, "Held item: %s\n", pokemon.HeldItem)
+	}
+	rarities := heldItemRarities(pokemon.Pokemon, c.Version)
+	if len(rarities) == 0 {
+		return
+	}
+	fmt.Fprintf(c.Out, "Possible held items (%s):\n", c.Version)
+	for _, held := range pokemon.HeldItems {
+		if rarity, ok := rarities[held.Item.Name]; ok {
+			fmt.Fprintf(c.Out, "  %s (%d%%)\n", held.Item.Name, rarity)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+	"github.com/glitchdawg/pokedex/internal/termimg"
+)
+
+const (
+	defaultHostAddr = ":2323"
+	// maxLoginAttempts is how many names or passwords a connection can get
+	// wrong before it is dropped.
+	maxLoginAttempts = 3
+	// passwordIterations is how many rounds of PBKDF2 a trainer's password
+	// goes through before it is stored.
+	passwordIterations = 100000
+	minPasswordLength  = 6
+	// defaultLoginTimeout is how long a connection has to log in before it
+	// is dropped, along with any name it has reserved.
+	defaultLoginTimeout = time.Minute
+	// defaultWriteTimeout is how long a write to a trainer can take before
+	// the trainer is taken to have stopped reading and is dropped.
+	defaultWriteTimeout = 10 * time.Second
+)
+
+var trainerNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,20}$`)
+
+// syncWriter lets broadcasts from other sessions write to a connection
+// without tearing the session's own output. A client that stops reading
+// would otherwise hold up every write to it for good, so a write that takes
+// longer than timeout closes the connection instead.
+type syncWriter struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	n, err := w.conn.Write(p)
+	if err != nil {
+		w.conn.Close()
+	}
+	return n, err
+}
+
+// hostSession is one trainer connected to a host. caught is kept here,
+// under the host's lock, so who never reads another session's Pokedex.
+type hostSession struct {
+	name   string
+	c      *config
+	since  time.Time
+	caught int
+}
+
+// host is a shared world. Every trainer has their own save, but sessions
+// share one cache and one HTTP client, so whatever one trainer fetches from
+// PokeAPI is there for everyone.
+type host struct {
+	mu       sync.Mutex
+	sessions map[string]*hostSession
+	// conns includes connections still choosing a trainer name.
+	conns  map[net.Conn]bool
+	cache  *pokecache.Cache
+	client *http.Client
+	// dir holds a save file and a password per trainer.
+	dir          string
+	loginTimeout time.Duration
+	writeTimeout time.Duration
+	wg           sync.WaitGroup
+}
+
+func newHost(cache *pokecache.Cache, client *http.Client, dir string) *host {
+	return &host{
+		sessions:     make(map[string]*hostSession),
+		conns:        make(map[net.Conn]bool),
+		cache:        cache,
+		client:       client,
+		dir:          dir,
+		loginTimeout: defaultLoginTimeout,
+		writeTimeout: defaultWriteTimeout,
+	}
+}
+
+// serve runs a session for every connection on ln until ln is closed.
+func (h *host) serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to accept connection: %v", err)
+		}
+		h.mu.Lock()
+		h.conns[conn] = true
+		h.mu.Unlock()
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.serveConn(conn)
+			h.mu.Lock()
+			delete(h.conns, conn)
+			h.mu.Unlock()
+		}()
+	}
+}
+
+func (h *host) serveConn(conn net.Conn) {
+	c := &config{Cache: h.cache, HTTP: h.client, Input: readLines(conn), Out: &syncWriter{conn: conn, timeout: h.writeTimeout}}
+	defer func() {
+		conn.Close()
+		// Let readLines see the closed connection and finish.
+		for range c.Input {
+		}
+	}()
+	fmt.Fprintln(c.Out, "Welcome to the shared Pokedex!")
+	// Once the deadline passes, reads fail and login gives up, so a
+	// connection can't sit on a name it hasn't proved is its own.
+	conn.SetReadDeadline(time.Now().Add(h.loginTimeout))
+	session := h.login(c)
+	if session == nil {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	defer h.leave(session)
+	c.SavePath = filepath.Join(h.dir, session.name+".json")
+	if err := loadGame(c); err != nil {
+		fmt.Fprintln(c.Out, "Error loading save:", err)
+		return
+	}
+	// Sessions can't change settings, but a save put in the trainers
+	// directory by hand could still name a program to play cries with, or
+	// leave graphics to be detected from the host's own terminal.
+	c.Settings.CryPlayer, c.Settings.CryOnCatch = "", false
+	c.Settings.Graphics = termimg.ModeANSI
+	h.mu.Lock()
+	session.caught = len(c.Pokedex)
+	h.mu.Unlock()
+	c.OnCatch = func(pokemon Pokemon, ball string, caught *CaughtPokemon) {
+		h.caught(session, caught)
+	}
+	h.broadcast(session, fmt.Sprintf("%s joined.", session.name))
+	fmt.Fprintf(c.Out, "Hello %s! Type help to get started, or who to see who's here.\n", session.name)
+	repl(c, h.commands())
+}
+
+// login asks for a trainer name and its password until it gets a pair that
+// matches and isn't already playing, and registers the session under the
+// name. A name nobody has used yet gets the password given for it.
+func (h *host) login(c *config) *hostSession {
+	for attempts := 0; attempts < maxLoginAttempts; attempts++ {
+		name, ok := prompt(c, "Trainer name: ")
+		if !ok {
+			return nil
+		}
+		if !trainerNamePattern.MatchString(name) {
+			fmt.Fprintln(c.Out, "Names are 1 to 20 letters, digits, dashes or underscores.")
+			continue
+		}
+		// The name is taken before the password is checked, so two
+		// connections can't both set a new trainer's password.
+		h.mu.Lock()
+		_, taken := h.sessions[name]
+		session := &hostSession{name: name, c: c, since: time.Now()}
+		if !taken {
+			h.sessions[name] = session
+		}
+		h.mu.Unlock()
+		if taken {
+			fmt.Fprintf(c.Out, "%s is already playing.\n", name)
+			continue
+		}
+		if !h.authenticate(c, name) {
+			h.mu.Lock()
+			delete(h.sessions, name)
+			h.mu.Unlock()
+			continue
+		}
+		return session
+	}
+	fmt.Fprintln(c.Out, "Too many tries, goodbye.")
+	return nil
+}
+
+// trainerPassword is what is kept of a trainer's password, next to their
+// save.
+type trainerPassword struct {
+	Salt []byte `json:"salt"`
+	Hash []byte `json:"hash"`
+}
+
+func hashPassword(password string, salt []byte) []byte {
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, sha256.Size)
+	if err != nil {
+		// Only key lengths no hash could produce fail.
+		panic(err)
+	}
+	return hash
+}
+
+// askPassword reads a password as typed, where prompt would clean it up.
+func askPassword(c *config, question string) (string, bool) {
+	fmt.Fprint(c.Out, question)
+	line, ok := <-c.Input
+	return strings.TrimSpace(line), ok
+}
+
+// authenticate checks the trainer's password, or has a new trainer choose
+// one.
+func (h *host) authenticate(c *config, name string) bool {
+	path := filepath.Join(h.dir, name+".password")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		password, ok := askPassword(c, "New trainer! Choose a password: ")
+		if !ok {
+			return false
+		}
+		if len(password) < minPasswordLength {
+			fmt.Fprintf(c.Out, "Passwords need at least %d characters.\n", minPasswordLength)
+			return false
+		}
+		stored := trainerPassword{Salt: make([]byte, 16)}
+		rand.Read(stored.Salt)
+		stored.Hash = hashPassword(password, stored.Salt)
+		data, _ := json.Marshal(stored)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			fmt.Fprintln(c.Out, "Error saving password:", err)
+			return false
+		}
+		return true
+	}
+	stored := trainerPassword{}
+	if err == nil {
+		err = json.Unmarshal(data, &stored)
+	}
+	if err != nil {
+		fmt.Fprintln(c.Out, "Error reading password:", err)
+		return false
+	}
+	password, ok := askPassword(c, "Password: ")
+	if !ok {
+		return false
+	}
+	if subtle.ConstantTimeCompare(hashPassword(password, stored.Salt), stored.Hash) != 1 {
+		fmt.Fprintln(c.Out, "Wrong password.")
+		return false
+	}
+	return true
+}
+
+func (h *host) leave(session *hostSession) {
+	h.mu.Lock()
+	delete(h.sessions, session.name)
+	h.mu.Unlock()
+	h.broadcast(session, fmt.Sprintf("%s left.", session.name))
+}
+
+// recipients lists every session but from, so they can be written to
+// without holding the host's lock.
+func (h *host) recipients(from *hostSession) []*hostSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sessions := []*hostSession{}
+	for _, session := range h.sessions {
+		if session != from {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// broadcast tells every session but from about something.
+func (h *host) broadcast(from *hostSession, message string) {
+	for _, session := range h.recipients(from) {
+		fmt.Fprintf(session.c.Out, "\n*** %s ***\n", message)
+	}
+}
+
+// rarity describes what makes a pokemon worth announcing, or is empty for
+// an everyday catch.
+func rarity(p *CaughtPokemon) string {
+	words := []string{}
+	if p.Shiny {
+		words = append(words, "shiny")
+	}
+	if p.Legendary {
+		words = append(words, "legendary")
+	}
+	if p.Mythical {
+		words = append(words, "mythical")
+	}
+	return strings.Join(words, " ")
+}
+
+func (h *host) caught(session *hostSession, caught *CaughtPokemon) {
+	if caught == nil {
+		return
+	}
+	h.mu.Lock()
+	session.caught++
+	h.mu.Unlock()
+	if rare := rarity(caught); rare != "" {
+		h.broadcast(session, fmt.Sprintf("%s caught a %s %s!", session.name, rare, caught.Name))
+	}
+}
+
+// hostCommandNames are the commands trainers on a host can run. They only
+// touch the trainer's own save and PokeAPI: anything that reads or writes the
+// host's files, opens connections from it, runs programs on it or changes
+// settings is left out.
+var hostCommandNames = []string{
+	"exit", "map", "mapb", "explore", "catch", "inspect", "pokedex", "battle",
+	"party", "move", "ability", "bag", "mart", "shinyhunt", "find", "compare",
+	"stats", "statcalc", "coverage", "suggest-team", "progress", "version",
+}
+
+func (h *host) commands() map[string]cliCommand {
+	all := cliCommands()
+	commands := make(map[string]cliCommand)
+	for _, name := range hostCommandNames {
+		commands[name] = all[name]
+	}
+	commands["help"] = cliCommand{
+		name:        "help",
+		description: "Show help information",
+		callback:    commandHostHelp,
+	}
+	commands["who"] = cliCommand{
+		name:        "who",
+		description: "List the trainers playing on this host",
+		callback:    h.commandWho,
+	}
+	return commands
+}
+
+// hostOnlyHelp is the help for the commands only a host has.
+const hostOnlyHelp = "who: List the trainers playing on this host"
+
+// commandHostHelp is help without the commands a host doesn't allow, and
+// with the ones only it has.
+func commandHostHelp(c *config, args []string) error {
+	all := cliCommands()
+	for _, line := range strings.Split(helpText, "\n") {
+		name, _, _ := strings.Cut(line, ":")
+		if fields := strings.Fields(name); len(fields) > 0 {
+			if _, isCommand := all[fields[0]]; isCommand && !contains(hostCommandNames, fields[0]) && fields[0] != "help" {
+				continue
+			}
+		}
+		fmt.Fprintln(c.Out, line)
+	}
+	fmt.Fprintln(c.Out, hostOnlyHelp)
+	return nil
+}
+
+func (h *host) commandWho(c *config, args []string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := []string{}
+	for name := range h.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(c.Out, "%d trainer(s) online:\n", len(names))
+	for _, name := range names {
+		session := h.sessions[name]
+		you := ""
+		if session.c == c {
+			you = " (you)"
+		}
+		fmt.Fprintf(c.Out, "- %s%s: %d caught, online for %s\n", name, you, session.caught, time.Since(session.since).Round(time.Second))
+	}
+	return nil
+}
+
+// shutdown ends every session. Each has saved after its last command, so
+// nothing is lost.
+func (h *host) shutdown() {
+	for _, session := range h.recipients(nil) {
+		fmt.Fprintln(session.c.Out, "\nThe host is shutting down. Goodbye!")
+	}
+	h.mu.Lock()
+	for conn := range h.conns {
+		conn.Close()
+	}
+	h.mu.Unlock()
+	h.wg.Wait()
+}
+
+// subcommandHost runs a shared world until it is interrupted. Trainers'
+// saves go in a trainers directory next to the host's own save.
+func subcommandHost(c *config, args []string) error {
+	flags := flag.NewFlagSet("host", flag.ContinueOnError)
+	addr := flags.String("addr", defaultHostAddr, "address to listen on")
+	metricsAddr := flags.String("metrics-addr", "", "address to serve Prometheus metrics on, if any")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	dir := filepath.Join(filepath.Dir(c.SavePath), "trainers")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create trainers directory: %v", err)
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+	if *metricsAddr != "" {
+		stopMetrics, err := startMetrics(*metricsAddr, c.Cache)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		defer stopMetrics()
+	}
+	h := newHost(c.Cache, httpClient(c), dir)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- h.serve(ln)
+	}()
+	fmt.Printf("Hosting a shared Pokedex on %s, connect with telnet or nc\n", ln.Addr())
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	fmt.Println("Shutting down...")
+	ln.Close()
+	<-errs
+	h.shutdown()
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+type hostClient struct {
+	conn net.Conn
+	seen []byte
+}
+
+func dialHost(t *testing.T, addr string) *hostClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &hostClient{conn: conn}
+}
+
+func (cl *hostClient) send(t *testing.T, line string) {
+	t.Helper()
+	if _, err := fmt.Fprintf(cl.conn, "%s\r\n", line); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expect reads until want turns up, and drops everything up to it.
+func (cl *hostClient) expect(t *testing.T, want string) {
+	t.Helper()
+	cl.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	for {
+		if i := bytes.Index(cl.seen, []byte(want)); i >= 0 {
+			cl.seen = cl.seen[i+len(want):]
+			return
+		}
+		n, err := cl.conn.Read(buf)
+		if err != nil {
+			t.Fatalf("expected %q, got %q (%v)", want, cl.seen, err)
+		}
+		cl.seen = append(cl.seen, buf[:n]...)
+	}
+}
+
+// startHost runs a host on loopback, after letting configure change its
+// timeouts.
+func startHost(t *testing.T, configure ...func(*host)) (*host, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHost(pokecache.NewCache(time.Hour), nil, t.TempDir())
+	for _, f := range configure {
+		f(h)
+	}
+	go h.serve(ln)
+	t.Cleanup(func() {
+		ln.Close()
+		h.shutdown()
+	})
+	return h, ln.Addr().String()
+}
+
+const testPassword = "Hunter 22"
+
+func login(t *testing.T, addr, name string) *hostClient {
+	t.Helper()
+	cl := dialHost(t, addr)
+	cl.expect(t, "Trainer name: ")
+	cl.send(t, name)
+	cl.expect(t, "assword: ")
+	cl.send(t, testPassword)
+	cl.expect(t, "Hello "+strings.ToLower(name))
+	cl.expect(t, "Pokedex > ")
+	return cl
+}
+
+func TestHostSessions(t *testing.T) {
+	h, addr := startHost(t)
+	alice := login(t, addr, "Alice")
+
+	bob := dialHost(t, addr)
+	bob.expect(t, "Trainer name: ")
+	bob.send(t, "not a name!")
+	bob.expect(t, "Names are")
+	bob.send(t, "alice")
+	bob.expect(t, "alice is already playing.")
+	bob.send(t, "bob")
+	bob.expect(t, "Choose a password: ")
+	bob.send(t, testPassword)
+	bob.expect(t, "Pokedex > ")
+	alice.expect(t, "*** bob joined. ***")
+
+	bob.send(t, "who")
+	bob.expect(t, "2 trainer(s) online:")
+	bob.expect(t, "- alice: 0 caught")
+	bob.expect(t, "- bob (you): 0 caught")
+
+	h.mu.Lock()
+	session := h.sessions["alice"]
+	h.mu.Unlock()
+	h.caught(session, &CaughtPokemon{Pokemon: Pokemon{Name: "rattata"}})
+	h.caught(session, &CaughtPokemon{Pokemon: Pokemon{Name: "mewtwo"}, Shiny: true, Legendary: true})
+	bob.expect(t, "*** alice caught a shiny legendary mewtwo! ***")
+	if bytes.Contains(bob.seen, []byte("rattata")) {
+		t.Errorf("expected everyday catches to stay quiet, got %q", bob.seen)
+	}
+	bob.send(t, "who")
+	bob.expect(t, "- alice: 2 caught")
+
+	alice.send(t, "exit")
+	alice.expect(t, "Goodbye!")
+	bob.expect(t, "*** alice left. ***")
+	if _, err := os.Stat(filepath.Join(h.dir, "alice.json")); err != nil {
+		t.Errorf("expected alice's own save: %v", err)
+	}
+}
+
+func TestHostSharesCache(t *testing.T) {
+	h, addr := startHost(t)
+	h.cache.Add(locationsURL(0), []byte(`{"count":1,"results":[{"name":"shared-area"}]}`))
+	alice := login(t, addr, "alice")
+	bob := login(t, addr, "bob")
+	alice.send(t, "map")
+	alice.expect(t, "shared-area")
+	bob.send(t, "map")
+	bob.expect(t, "shared-area")
+}
+
+func TestHostCommandsAreSafe(t *testing.T) {
+	h, addr := startHost(t)
+	commands := h.commands()
+	for _, name := range []string{"settings", "export", "export-showdown", "import-showdown", "trade", "host-battle", "join-battle", "cry", "sprite"} {
+		if _, ok := commands[name]; ok {
+			t.Errorf("expected %s to be unavailable on a host", name)
+		}
+	}
+
+	alice := login(t, addr, "alice")
+	alice.send(t, "settings cry-player touch pwned")
+	alice.expect(t, "Unknown command: settings")
+
+	out := &bytes.Buffer{}
+	if err := commandHostHelp(&config{Out: out}, nil); err != nil {
+		t.Fatal(err)
+	}
+	help := out.String()
+	if !strings.Contains(help, "\nwho:") || !strings.Contains(help, "\ncatch <pokemon>") || strings.Contains(help, "\nexport") || strings.Contains(help, "\nsettings") {
+		t.Errorf("expected help to list only the host's commands, got\n%s", help)
+	}
+}
+
+func TestHostPasswords(t *testing.T) {
+	_, addr := startHost(t)
+	alice := login(t, addr, "alice")
+	alice.send(t, "exit")
+	alice.expect(t, "Goodbye!")
+
+	mallory := dialHost(t, addr)
+	mallory.expect(t, "Trainer name: ")
+	mallory.send(t, "alice")
+	mallory.expect(t, "Password: ")
+	// Passwords keep their case.
+	mallory.send(t, strings.ToLower(testPassword))
+	mallory.expect(t, "Wrong password.")
+	mallory.send(t, "mallory")
+	mallory.expect(t, "Choose a password: ")
+	mallory.send(t, "123")
+	mallory.expect(t, "at least")
+	mallory.send(t, "alice")
+	mallory.expect(t, "Password: ")
+	mallory.send(t, "guess")
+	mallory.expect(t, "Too many tries, goodbye.")
+
+	login(t, addr, "alice")
+}
+
+func TestHostDropsClientsThatStopReading(t *testing.T) {
+	_, addr := startHost(t, func(h *host) { h.writeTimeout = 200 * time.Millisecond })
+	alice := login(t, addr, "alice")
+	slow := login(t, addr, "slow")
+	alice.expect(t, "slow joined.")
+	// slow asks for help over and over without reading any of it, until
+	// the connection backs up.
+	go func() {
+		flood := []byte(strings.Repeat("help\r\n", 1000))
+		for {
+			if _, err := slow.conn.Write(flood); err != nil {
+				return
+			}
+		}
+	}()
+	time.Sleep(time.Second)
+
+	login(t, addr, "bob")
+	alice.expect(t, "bob joined.")
+	alice.send(t, "who")
+	alice.expect(t, "online:")
+}
+
+func TestHostLoginTimeout(t *testing.T) {
+	_, addr := startHost(t, func(h *host) { h.loginTimeout = 500 * time.Millisecond })
+	first := login(t, addr, "alice")
+	first.send(t, "exit")
+	first.expect(t, "Goodbye!")
+
+	// Someone who knows the name but not the password can't keep alice out
+	// by sitting at the prompt.
+	squatter := dialHost(t, addr)
+	squatter.expect(t, "Trainer name: ")
+	squatter.send(t, "alice")
+	squatter.expect(t, "Password: ")
+	squatter.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadAll(squatter.conn); err != nil {
+		t.Fatalf("expected the host to hang up, got %v", err)
+	}
+	login(t, addr, "alice")
+}
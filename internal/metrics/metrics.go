@@ -0,0 +1,153 @@
+// Package metrics writes metrics in the Prometheus text exposition format,
+// https://prometheus.io/docs/instrumenting/exposition_formats/.
+//
+// Counters and gauges whose values live elsewhere, like a cache's hit count,
+// are written straight from those values with WriteCounter and WriteGauge.
+// Histogram keeps its own observations.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suits request latencies in seconds, from a cache-warm 5ms
+// to a struggling 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into buckets, separately for every
+// combination of label values.
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	// counts[i] is the number of observations in bucket i alone; the last
+	// count is for observations above every bucket.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram makes a histogram with upper bounds buckets, in increasing
+// order, and the given label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*series)}
+}
+
+// Observe records value under labelValues, given in the order of the
+// histogram's label names.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", h.name, len(h.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &series{labelValues: labelValues, counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[sort.SearchFloat64s(h.buckets, value)]++
+	s.sum += value
+	s.count++
+}
+
+// WriteTo writes the histogram's buckets, sum and count for every label
+// combination it has seen.
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	b := &strings.Builder{}
+	writeHeader(b, h.name, h.help, "histogram")
+	for _, key := range keys {
+		s := h.series[key]
+		cumulative := uint64(0)
+		for i, count := range s.counts {
+			cumulative += count
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, labelSet(h.labels, s.labelValues, "le", formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, labelSet(h.labels, s.labelValues), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, labelSet(h.labels, s.labelValues), s.count)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteCounter writes a counter, which only ever goes up. By convention its
+// name ends in _total.
+func WriteCounter(w io.Writer, name, help string, value float64) error {
+	return writeSingle(w, name, help, "counter", value)
+}
+
+// WriteGauge writes a gauge, a value that can go up and down.
+func WriteGauge(w io.Writer, name, help string, value float64) error {
+	return writeSingle(w, name, help, "gauge", value)
+}
+
+func writeSingle(w io.Writer, name, help, kind string, value float64) error {
+	b := &strings.Builder{}
+	writeHeader(b, name, help, kind)
+	fmt.Fprintf(b, "%s %s\n", name, formatFloat(value))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func writeHeader(b *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, kind)
+}
+
+// labelSet formats names and values, then any extra name-value pairs, as
+// {name="value",...}.
+func labelSet(names, values []string, extra ...string) string {
+	pairs := []string{}
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], labelEscaper.Replace(extra[i+1])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
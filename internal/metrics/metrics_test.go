@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("latency_seconds", "How long things took.", []float64{0.1, 1}, "endpoint", "status")
+	h.Observe(0.05, "pokemon", "200")
+	h.Observe(0.1, "pokemon", "200")
+	h.Observe(3, "pokemon", "200")
+	h.Observe(0.5, `we"ird`, "error")
+	out := &strings.Builder{}
+	if _, err := h.WriteTo(out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP latency_seconds How long things took.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{endpoint="pokemon",status="200",le="0.1"} 2
+latency_seconds_bucket{endpoint="pokemon",status="200",le="1"} 2
+latency_seconds_bucket{endpoint="pokemon",status="200",le="+Inf"} 3
+latency_seconds_sum{endpoint="pokemon",status="200"} 3.15
+latency_seconds_count{endpoint="pokemon",status="200"} 3
+latency_seconds_bucket{endpoint="we\"ird",status="error",le="0.1"} 0
+latency_seconds_bucket{endpoint="we\"ird",status="error",le="1"} 1
+latency_seconds_bucket{endpoint="we\"ird",status="error",le="+Inf"} 1
+latency_seconds_sum{endpoint="we\"ird",status="error"} 0.5
+latency_seconds_count{endpoint="we\"ird",status="error"} 1
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	out := &strings.Builder{}
+	WriteCounter(out, "hits_total", "Lookups that\nhit.", 3)
+	WriteGauge(out, "bytes", "Bytes held.", 1.5e6)
+	want := `# HELP hits_total Lookups that\nhit.
+# TYPE hits_total counter
+hits_total 3
+# HELP bytes Bytes held.
+# TYPE bytes gauge
+bytes 1.5e+06
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -57,4 +57,37 @@ func TestReapLoop(t *testing.T){
 	}
 
 
-}
\ No newline at end of file
+}
+func TestStats(t *testing.T) {
+	// A long interval keeps the reaper out of the way while the counts are
+	// checked.
+	cache := NewCache(time.Hour)
+	cache.Add("a", []byte("1234"))
+	cache.Add("b", []byte("56"))
+	cache.Add("a", []byte("123"))
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("missing")
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 2 || stats.Bytes != 5 || stats.Evictions != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestStatsEvictions(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	cache := NewCache(interval)
+	cache.Add("a", []byte("123"))
+	cache.Add("b", []byte("56"))
+	// The reaper runs on its own schedule, so give it a while rather than
+	// an exact number of intervals.
+	deadline := time.Now().Add(5 * time.Second)
+	stats := cache.Stats()
+	for stats.Entries > 0 && time.Now().Before(deadline) {
+		time.Sleep(interval)
+		stats = cache.Stats()
+	}
+	if stats.Evictions != 2 || stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("expected everything to be evicted, got %+v", stats)
+	}
+}
@@ -8,6 +8,18 @@ type Cache struct {
 	cache map[string]cacheEntry
 	mu	   sync.Mutex
 	interval time.Duration
+	stats Stats
+}
+
+// Stats counts how well a cache is doing since it was made.
+type Stats struct {
+	Hits uint64
+	Misses uint64
+	// Evictions counts entries dropped for being older than the interval.
+	Evictions uint64
+	// Entries and Bytes are what the cache holds right now.
+	Entries int
+	Bytes int
 }
 type cacheEntry struct {
 	createdAt time.Time
@@ -27,6 +39,12 @@ func NewCache(interval time.Duration) *Cache {
 func (c *Cache) Add(key string, val []byte){
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if old, ok := c.cache[key]; ok {
+		c.stats.Bytes -= len(old.val)
+		c.stats.Entries--
+	}
+	c.stats.Bytes += len(val)
+	c.stats.Entries++
 	c.cache[key] = cacheEntry{
 		createdAt: time.Now(),
 		val: val,
@@ -38,9 +56,11 @@ func (c *Cache) Get(key string) ([]byte, bool){
 	defer c.mu.Unlock()
 	entry,ok:=c.cache[key]
 	if !ok{
+		c.stats.Misses++
 		return []byte{}, false
 	}
-	
+	c.stats.Hits++
+
 	return entry.val, true
 }
 func (c *Cache) reapLoop(){
@@ -53,9 +73,19 @@ func (c *Cache) reapLoop(){
 			for key, entry := range c.cache {
 				if now.Sub(entry.createdAt) > c.interval {
 					delete(c.cache, key)
+					c.stats.Evictions++
+					c.stats.Entries--
+					c.stats.Bytes -= len(entry.val)
 				}
 			}
 			c.mu.Unlock()
 		}
 	}()
-}
\ No newline at end of file
+}
+
+// Stats reports the cache's counters so far.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
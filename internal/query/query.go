@@ -0,0 +1,368 @@
+// Package query implements the small expression language used by the find
+// command, e.g. `type=water and speed>90 and not legendary order by attack desc`.
+//
+// Grammar:
+//
+//	query   = [or] ["order" "by" field ["asc" | "desc"]] ["limit" number]
+//	or      = and {"or" and}
+//	and     = not {"and" not}
+//	not     = "not" not | primary
+//	primary = "(" or ")" | field op value | flag
+//	op      = "=" | "!=" | "<" | "<=" | ">" | ">="
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a field, which decides how it can be used.
+type Kind int
+
+const (
+	// Number fields can be compared with any operator.
+	Number Kind = iota
+	// Text fields can only be tested with = and !=. A record may have several
+	// values for one text field; = matches if any of them is equal.
+	Text
+	// Flag fields are true or false and are used on their own.
+	Flag
+)
+
+// Schema lists the fields a query may use.
+type Schema map[string]Kind
+
+// Record is something a query is evaluated against.
+type Record interface {
+	Number(field string) int
+	Text(field string) []string
+	Flag(field string) bool
+}
+
+// Query is a parsed query.
+type Query struct {
+	where   node
+	OrderBy string
+	Desc    bool
+	Limit   int
+}
+
+// Match reports whether a record satisfies the query's conditions. A query
+// without conditions matches everything.
+func (q *Query) Match(r Record) bool {
+	if q.where == nil {
+		return true
+	}
+	return q.where.eval(r)
+}
+
+// SyntaxError describes a problem with a query and where it was found.
+type SyntaxError struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s\n  %s\n  %s^", e.Pos+1, e.Msg, e.Query, strings.Repeat(" ", e.Pos))
+}
+
+type node interface {
+	eval(r Record) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ operand node }
+type flagNode struct{ field string }
+type numberNode struct {
+	field string
+	op    string
+	value int
+}
+type textNode struct {
+	field  string
+	negate bool
+	value  string
+}
+
+func (n andNode) eval(r Record) bool  { return n.left.eval(r) && n.right.eval(r) }
+func (n orNode) eval(r Record) bool   { return n.left.eval(r) || n.right.eval(r) }
+func (n notNode) eval(r Record) bool  { return !n.operand.eval(r) }
+func (n flagNode) eval(r Record) bool { return r.Flag(n.field) }
+
+func (n numberNode) eval(r Record) bool {
+	v := r.Number(n.field)
+	switch n.op {
+	case "=":
+		return v == n.value
+	case "!=":
+		return v != n.value
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	}
+	return false
+}
+
+func (n textNode) eval(r Record) bool {
+	for _, v := range r.Text(n.field) {
+		if v == n.value {
+			return !n.negate
+		}
+	}
+	return n.negate
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "the end of the query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isWordChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_'
+}
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t':
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case ch == '=' || ch == '<' || ch == '>' || ch == '!':
+			op := string(ch)
+			if ch != '=' && i+1 < len(src) && src[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &SyntaxError{src, i, `expected "!=", use "not" to negate a condition`}
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len(op)
+		case isWordChar(ch):
+			start := i
+			for i < len(src) && isWordChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, src[start:i], start})
+		default:
+			return nil, &SyntaxError{src, i, fmt.Sprintf("unexpected character %q", ch)}
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(src)}), nil
+}
+
+var keywords = map[string]bool{"and": true, "or": true, "not": true, "order": true, "by": true, "asc": true, "desc": true, "limit": true}
+
+type parser struct {
+	src    string
+	schema Schema
+	tokens []token
+	pos    int
+}
+
+// Parse parses a query, checking the fields it uses against schema.
+func Parse(src string, schema Schema) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, schema: schema, tokens: tokens}
+	q := &Query{}
+	if !p.isWord("order") && !p.isWord("limit") && p.peek().kind != tokenEOF {
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isWord("order") {
+		p.next()
+		if !p.isWord("by") {
+			return nil, p.errorf(p.peek(), `expected "by" after "order" but found %s`, p.peek())
+		}
+		p.next()
+		field := p.next()
+		if field.kind != tokenWord || keywords[field.text] {
+			return nil, p.errorf(field, "expected a field to order by but found %s", field)
+		}
+		kind, ok := schema[field.text]
+		if !ok {
+			return nil, p.unknownField(field)
+		}
+		if kind == Flag {
+			return nil, p.errorf(field, "can't order by %s because it is a flag", field.text)
+		}
+		q.OrderBy = field.text
+		if p.isWord("desc") {
+			q.Desc = true
+			p.next()
+		} else if p.isWord("asc") {
+			p.next()
+		}
+	}
+	if p.isWord("limit") {
+		p.next()
+		n := p.next()
+		limit, err := strconv.Atoi(n.text)
+		if n.kind != tokenWord || err != nil || limit < 1 {
+			return nil, p.errorf(n, "expected a positive number after limit but found %s", n)
+		}
+		q.Limit = limit
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, `unexpected %s, expected "and", "or", "order by" or "limit"`, t)
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isWord(word string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && t.text == word
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Query: p.src, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) unknownField(t token) error {
+	fields := make([]string, 0, len(p.schema))
+	for name := range p.schema {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return p.errorf(t, "unknown field %q, known fields are %s", t.text, strings.Join(fields, ", "))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isWord("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, `expected ")" to close the "(" at column %d but found %s`, t.pos+1, closing)
+		}
+		return inner, nil
+	case t.kind != tokenWord || keywords[t.text]:
+		return nil, p.errorf(t, "expected a condition but found %s", t)
+	}
+
+	kind, ok := p.schema[t.text]
+	if !ok {
+		return nil, p.unknownField(t)
+	}
+	if p.peek().kind != tokenOp {
+		if kind != Flag {
+			return nil, p.errorf(p.peek(), "expected a comparison after %s, such as %s=...", t.text, t.text)
+		}
+		return flagNode{t.text}, nil
+	}
+	op := p.next()
+	if kind == Flag {
+		return nil, p.errorf(op, `%s is a flag, use "%s" or "not %s" instead of comparing it`, t.text, t.text, t.text)
+	}
+	value := p.next()
+	if value.kind != tokenWord || keywords[value.text] {
+		return nil, p.errorf(value, "expected a value after %q but found %s", op.text, value)
+	}
+	if kind == Text {
+		if op.text != "=" && op.text != "!=" {
+			return nil, p.errorf(op, "%s can only be compared with = or !=", t.text)
+		}
+		return textNode{field: t.text, negate: op.text == "!=", value: value.text}, nil
+	}
+	n, err := strconv.Atoi(value.text)
+	if err != nil {
+		return nil, p.errorf(value, "%s is a number but %q is not", t.text, value.text)
+	}
+	return numberNode{field: t.text, op: op.text, value: n}, nil
+}
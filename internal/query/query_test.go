@@ -0,0 +1,133 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+var testSchema = Schema{
+	"name":      Text,
+	"type":      Text,
+	"speed":     Number,
+	"attack":    Number,
+	"legendary": Flag,
+	"shiny":     Flag,
+}
+
+type testRecord struct {
+	numbers map[string]int
+	texts   map[string][]string
+	flags   map[string]bool
+}
+
+func (r testRecord) Number(field string) int    { return r.numbers[field] }
+func (r testRecord) Text(field string) []string { return r.texts[field] }
+func (r testRecord) Flag(field string) bool     { return r.flags[field] }
+
+var (
+	starmie = testRecord{
+		numbers: map[string]int{"speed": 115, "attack": 75},
+		texts:   map[string][]string{"name": {"starmie"}, "type": {"water", "psychic"}},
+	}
+	suicune = testRecord{
+		numbers: map[string]int{"speed": 85, "attack": 75},
+		texts:   map[string][]string{"name": {"suicune"}, "type": {"water"}},
+		flags:   map[string]bool{"legendary": true},
+	}
+	jolteon = testRecord{
+		numbers: map[string]int{"speed": 130, "attack": 65},
+		texts:   map[string][]string{"name": {"jolteon"}, "type": {"electric"}},
+		flags:   map[string]bool{"shiny": true},
+	}
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected []bool // starmie, suicune, jolteon
+	}{
+		{query: "", expected: []bool{true, true, true}},
+		{query: "type=water", expected: []bool{true, true, false}},
+		{query: "type=water and speed>90 and not legendary", expected: []bool{true, false, false}},
+		{query: "type = psychic or shiny", expected: []bool{true, false, true}},
+		{query: "not (type=water or speed<100)", expected: []bool{false, false, true}},
+		{query: "type!=water and attack<=65", expected: []bool{false, false, true}},
+		{query: "speed>=85 and speed<=115 and attack=75", expected: []bool{true, true, false}},
+		{query: "not not legendary", expected: []bool{false, true, false}},
+		{query: "shiny or legendary and speed>100", expected: []bool{false, false, true}},
+	}
+	for _, c := range cases {
+		q, err := Parse(c.query, testSchema)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.query, err)
+		}
+		for i, r := range []testRecord{starmie, suicune, jolteon} {
+			if actual := q.Match(r); actual != c.expected[i] {
+				t.Errorf("%q on %s: expected %v, got %v", c.query, r.texts["name"][0], c.expected[i], actual)
+			}
+		}
+	}
+}
+
+func TestOrderAndLimit(t *testing.T) {
+	q, err := Parse("type=water order by attack desc limit 3", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.OrderBy != "attack" || !q.Desc || q.Limit != 3 {
+		t.Errorf("expected order by attack desc limit 3, got %+v", q)
+	}
+	q, err = Parse("order by name", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.OrderBy != "name" || q.Desc || !q.Match(jolteon) {
+		t.Errorf("expected an unconditional query ordered by name, got %+v", q)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{query: "type=", pos: 5, msg: "expected a value"},
+		{query: "speed>fast", pos: 6, msg: "is a number"},
+		{query: "colour=red", pos: 0, msg: `unknown field "colour"`},
+		{query: "type>water", pos: 4, msg: "can only be compared with = or !="},
+		{query: "legendary=yes", pos: 9, msg: "is a flag"},
+		{query: "speed", pos: 5, msg: "expected a comparison"},
+		{query: "(type=water", pos: 11, msg: `expected ")"`},
+		{query: "type=water speed>90", pos: 11, msg: `unexpected "speed"`},
+		{query: "type=water and", pos: 14, msg: "expected a condition"},
+		{query: "order attack", pos: 6, msg: `expected "by"`},
+		{query: "order by shiny", pos: 9, msg: "is a flag"},
+		{query: "limit 0", pos: 6, msg: "positive number"},
+		{query: "speed!90", pos: 5, msg: `expected "!="`},
+		{query: "type=water;", pos: 10, msg: "unexpected character"},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.query, testSchema)
+		if err == nil {
+			t.Errorf("%q: expected an error", c.query)
+			continue
+		}
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: expected a SyntaxError, got %T", c.query, err)
+			continue
+		}
+		if syntaxErr.Pos != c.pos || !strings.Contains(syntaxErr.Msg, c.msg) {
+			t.Errorf("%q: expected %q at %d, got %q at %d", c.query, c.msg, c.pos, syntaxErr.Msg, syntaxErr.Pos)
+		}
+	}
+}
+
+func TestSyntaxErrorPointsAtColumn(t *testing.T) {
+	_, err := Parse("speed>", testSchema)
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 || lines[1] != "  speed>" || lines[2] != "        ^" {
+		t.Errorf("unexpected error layout:\n%s", err)
+	}
+}
@@ -0,0 +1,25 @@
+package termimg
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	defaultCols       = 80
+	defaultRows       = 24
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+)
+
+// envSize falls back to $COLUMNS and $LINES when the terminal can't be asked.
+func envSize() (cols, rows int) {
+	cols, rows = defaultCols, defaultRows
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		rows = n
+	}
+	return cols, rows
+}
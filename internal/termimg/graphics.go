@@ -0,0 +1,160 @@
+package termimg
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+)
+
+// Modes an image can be drawn in, from best to worst fidelity.
+const (
+	ModeKitty = "kitty"
+	ModeSixel = "sixel"
+	ModeANSI  = "ansi"
+	ModeText  = "text"
+)
+
+// kittyChunkSize is the largest payload the kitty graphics protocol accepts
+// in one escape sequence.
+const kittyChunkSize = 4096
+
+// Detect guesses the best mode the terminal supports from the environment.
+// Terminals can't be asked without putting them in raw mode, so this errs on
+// the side of ANSI art.
+func Detect() string {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("NO_COLOR") != "" || term == "dumb":
+		return ModeText
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty" || program == "WezTerm":
+		return ModeKitty
+	case strings.Contains(term, "sixel") || term == "foot" || term == "mlterm" || program == "iTerm.app":
+		return ModeSixel
+	}
+	return ModeANSI
+}
+
+// Kitty draws a PNG with the kitty graphics protocol, scaled by the terminal
+// to cols cells wide.
+func Kitty(png []byte, cols int) string {
+	payload := base64.StdEncoding.EncodeToString(png)
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Gf=100,a=T,c=%d,m=%d;%s\x1b\\", cols, more, payload[i:end])
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// FitCells returns how many cells wide an image of the given pixel size is
+// drawn so that it fits in cols by rows cells without being scaled up.
+func FitCells(width, height, cols, rows int) int {
+	cw, ch := CellSize()
+	w, _ := fitBox(width, height, cols*cw, rows*ch)
+	return max(w/cw, 1)
+}
+
+// ScaleToCells scales an image down to fit in cols by rows cells, for
+// protocols like sixel where the terminal draws pixels as given.
+func ScaleToCells(img image.Image, cols, rows int) image.Image {
+	cw, ch := CellSize()
+	width, height := fitBox(img.Bounds().Dx(), img.Bounds().Dy(), cols*cw, rows*ch)
+	return Scale(img, width, height)
+}
+
+// Scale resizes an image to width by height pixels with nearest neighbour
+// sampling.
+func Scale(img image.Image, width, height int) image.Image {
+	b := img.Bounds()
+	if width == b.Dx() && height == b.Dy() {
+		return img
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, img.At(b.Min.X+x*b.Dx()/width, b.Min.Y+y*b.Dy()/height))
+		}
+	}
+	return scaled
+}
+
+// Sixel encodes an image as sixel graphics using a 6x6x6 colour cube.
+// Transparent pixels are left as the terminal background.
+func Sixel(img image.Image) string {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	index := func(x, y int) int {
+		c := img.At(b.Min.X+x, b.Min.Y+y)
+		if !opaque(c) {
+			return -1
+		}
+		n := color.NRGBAModel.Convert(c).(color.NRGBA)
+		level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+		return level(n.R)*36 + level(n.G)*6 + level(n.B)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+	for top := 0; top < height; top += 6 {
+		bands := make(map[int][]byte)
+		order := []int{}
+		for x := 0; x < width; x++ {
+			for bit := 0; bit < 6 && top+bit < height; bit++ {
+				i := index(x, top+bit)
+				if i < 0 {
+					continue
+				}
+				if _, ok := bands[i]; !ok {
+					bands[i] = make([]byte, width)
+					order = append(order, i)
+				}
+				bands[i][x] |= 1 << bit
+			}
+		}
+		for n, i := range order {
+			if n > 0 {
+				sb.WriteByte('$')
+			}
+			fmt.Fprintf(&sb, "#%d", i)
+			writeSixelRow(&sb, bands[i])
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\\n")
+	return sb.String()
+}
+
+// writeSixelRow writes one colour of a band, run-length encoding repeats.
+func writeSixelRow(sb *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		run := 1
+		for x+run < len(row) && row[x+run] == row[x] {
+			run++
+		}
+		ch := byte('?' + row[x])
+		if run > 3 {
+			fmt.Fprintf(sb, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				sb.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
@@ -0,0 +1,45 @@
+package termimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestKittyChunks(t *testing.T) {
+	out := Kitty(bytes.Repeat([]byte{0xff}, 4000), 20)
+	if !strings.HasPrefix(out, "\x1b_Gf=100,a=T,c=20,m=1;") {
+		t.Errorf("expected the first chunk to carry the image options, got %q", out[:30])
+	}
+	if strings.Count(out, "\x1b_G") != 2 {
+		t.Errorf("expected 2 chunks, got %d", strings.Count(out, "\x1b_G"))
+	}
+	if !strings.Contains(out, "\x1b_Gm=0;") {
+		t.Error("expected the last chunk to end the transmission")
+	}
+}
+
+func TestSixel(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 5, 2))
+	for x := 0; x < 5; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	img.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	out := Sixel(img)
+	if !strings.HasPrefix(out, "\x1bP0;1;0q\"1;1;5;2") {
+		t.Errorf("unexpected sixel header: %q", out[:20])
+	}
+	// Red is cube index 180 and fills the top row of the band; blue (index 5)
+	// only the second row of the first column.
+	if !strings.Contains(out, "#180!5@") {
+		t.Error("expected a run-length encoded red row")
+	}
+	if !strings.Contains(out, "$#5A") {
+		t.Error("expected blue to overlay the band after a carriage return")
+	}
+	if !strings.HasSuffix(out, "-\x1b\\\n") {
+		t.Error("expected the sixel image to be terminated")
+	}
+}
@@ -0,0 +1,108 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// alphaThreshold is the alpha below which a pixel is drawn as transparent.
+const alphaThreshold = 0x8000
+
+// Crop trims fully transparent rows and columns from the edges of an image.
+// Sprites are padded generously, so this makes the art a lot more compact.
+func Crop(img image.Image) image.Image {
+	b := img.Bounds()
+	minX, minY, maxX, maxY := b.Max.X, b.Max.Y, b.Min.X-1, b.Min.Y-1
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if opaque(img.At(x, y)) {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+	if maxX < minX {
+		return img
+	}
+	sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	})
+	if !ok {
+		return img
+	}
+	return sub.SubImage(image.Rect(minX, minY, maxX+1, maxY+1))
+}
+
+// Fit returns the size in pixels an image is scaled to so that it fits in a
+// box of cols by rows terminal cells of half-block art, keeping its aspect
+// ratio and never scaling up.
+func Fit(img image.Image, cols, rows int) (width, height int) {
+	return fitBox(img.Bounds().Dx(), img.Bounds().Dy(), cols, rows*2)
+}
+
+func fitBox(width, height, maxWidth, maxHeight int) (int, int) {
+	if maxWidth > 0 && width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if maxHeight > 0 && height > maxHeight {
+		width = width * maxHeight / height
+		height = maxHeight
+	}
+	return max(width, 1), max(height, 1)
+}
+
+// HalfBlocks renders an image as truecolor ANSI art no larger than cols by
+// rows cells. Every cell is an upper half block whose foreground is one pixel
+// and whose background is the pixel below it.
+func HalfBlocks(img image.Image, cols, rows int) string {
+	b := img.Bounds()
+	width, height := Fit(img, cols, rows)
+	at := func(x, y int) color.Color {
+		if y >= height {
+			return color.Transparent
+		}
+		return img.At(b.Min.X+x*b.Dx()/width, b.Min.Y+y*b.Dy()/height)
+	}
+
+	var sb strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			top, bottom := at(x, y), at(x, y+1)
+			switch {
+			case opaque(top) && opaque(bottom):
+				sb.WriteString(fg(top) + bg(bottom) + "▀")
+			case opaque(top):
+				sb.WriteString("\x1b[49m" + fg(top) + "▀")
+			case opaque(bottom):
+				sb.WriteString("\x1b[49m" + fg(bottom) + "▄")
+			default:
+				sb.WriteString("\x1b[0m ")
+			}
+		}
+		sb.WriteString("\x1b[0m\n")
+	}
+	return sb.String()
+}
+
+func opaque(c color.Color) bool {
+	_, _, _, a := c.RGBA()
+	return a >= alphaThreshold
+}
+
+func rgb(c color.Color) (r, g, b uint8) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B
+}
+
+func fg(c color.Color) string {
+	r, g, b := rgb(c)
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+func bg(c color.Color) string {
+	r, g, b := rgb(c)
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b)
+}
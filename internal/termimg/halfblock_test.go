@@ -0,0 +1,54 @@
+package termimg
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestCrop(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(3, 4, color.NRGBA{R: 255, A: 255})
+	img.Set(6, 5, color.NRGBA{G: 255, A: 255})
+	cropped := Crop(img)
+	if cropped.Bounds() != image.Rect(3, 4, 7, 6) {
+		t.Errorf("expected bounds (3,4)-(7,6), got %v", cropped.Bounds())
+	}
+}
+
+func TestFit(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 96, 48))
+	cases := []struct {
+		cols, rows    int
+		width, height int
+	}{
+		{cols: 200, rows: 100, width: 96, height: 48},
+		{cols: 48, rows: 100, width: 48, height: 24},
+		{cols: 200, rows: 12, width: 48, height: 24},
+	}
+	for _, c := range cases {
+		width, height := Fit(img, c.cols, c.rows)
+		if width != c.width || height != c.height {
+			t.Errorf("fit in %dx%d: expected %dx%d, got %dx%d", c.cols, c.rows, c.width, c.height, width, height)
+		}
+	}
+}
+
+func TestHalfBlocks(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	img.Set(1, 1, color.NRGBA{G: 255, A: 255})
+	out := HalfBlocks(img, 80, 24)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one line of art, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀") {
+		t.Errorf("expected red over blue in the first cell, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;0;255;0m▄") {
+		t.Errorf("expected a green lower half block in the second cell, got %q", lines[0])
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package termimg
+
+// Size returns the size of the terminal in cells as far as the environment
+// tells us.
+func Size() (cols, rows int) {
+	return envSize()
+}
+
+// CellSize returns the usual size of a terminal cell in pixels.
+func CellSize() (width, height int) {
+	return defaultCellWidth, defaultCellHeight
+}
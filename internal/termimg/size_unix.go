@@ -0,0 +1,48 @@
+//go:build linux || darwin
+
+package termimg
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	Row, Col, X, Y uint16
+}
+
+func getWinsize() (winsize, bool) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	return ws, errno == 0 && ws.Col > 0 && ws.Row > 0
+}
+
+// Size returns the size of the terminal attached to stdout in cells.
+func Size() (cols, rows int) {
+	ws, ok := getWinsize()
+	if !ok {
+		return envSize()
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// CellSize returns the size of one terminal cell in pixels, for protocols
+// that draw pixels rather than cells.
+func CellSize() (width, height int) {
+	ws, ok := getWinsize()
+	if !ok {
+		return defaultCellWidth, defaultCellHeight
+	}
+	return cellSize(ws)
+}
+
+// cellSize works out the cell size from a window size. Some terminals
+// report no pixel size, or one smaller than the cell counts, which would
+// make a cell 0 pixels across; those fall back to the default.
+func cellSize(ws winsize) (width, height int) {
+	if ws.Col == 0 || ws.Row == 0 || ws.X/ws.Col == 0 || ws.Y/ws.Row == 0 {
+		return defaultCellWidth, defaultCellHeight
+	}
+	return int(ws.X / ws.Col), int(ws.Y / ws.Row)
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package termimg
+
+import "testing"
+
+func TestCellSize(t *testing.T) {
+	cases := []struct {
+		ws            winsize
+		width, height int
+	}{
+		{winsize{Row: 50, Col: 200, X: 1600, Y: 1000}, 8, 20},
+		{winsize{Row: 50, Col: 200}, defaultCellWidth, defaultCellHeight},
+		{winsize{Row: 50, Col: 200, X: 100, Y: 1000}, defaultCellWidth, defaultCellHeight},
+		{winsize{Row: 50, Col: 200, X: 1600, Y: 30}, defaultCellWidth, defaultCellHeight},
+	}
+	for _, c := range cases {
+		if w, h := cellSize(c.ws); w != c.width || h != c.height {
+			t.Errorf("cellSize(%+v) = %d, %d, want %d, %d", c.ws, w, h, c.width, c.height)
+		}
+	}
+}
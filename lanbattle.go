@@ -0,0 +1,763 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lanProtocolVersion is bumped whenever battle messages change; both sides
+// must speak the same version.
+const lanProtocolVersion = 2
+
+const (
+	defaultBattleAddr = ":7777"
+	defaultTurnTimer  = 60 * time.Second
+	// turnGrace is how much longer than the turn timer a side waits for the
+	// other's move before assuming the connection is gone.
+	turnGrace        = 10 * time.Second
+	reconnectWindow  = 60 * time.Second
+	handshakeTimeout = 10 * time.Second
+	forfeitMove      = -1
+)
+
+var errDesync = errors.New("the battle fell out of sync, both sides saw different results")
+
+// battlerSpec is how a pokemon is described to the other side. Each side
+// fetches the species and moves itself and works out the stats, so neither
+// has to take the other's word for them.
+type battlerSpec struct {
+	Name   string         `json:"name"`
+	Level  int            `json:"level"`
+	IVs    map[string]int `json:"ivs"`
+	EVs    map[string]int `json:"evs"`
+	Nature string         `json:"nature"`
+	Moves  []string       `json:"moves"`
+}
+
+// lanMessage is one line of the battle protocol. Guests open with hello and
+// the host answers with start; then both sides send a move every turn along
+// with a checksum of the battle as they see it before the turn. A guest that
+// lost its connection sends resume and the host answers with resumed.
+type lanMessage struct {
+	Type      string        `json:"type"`
+	Version   int           `json:"version,omitempty"`
+	Trainer   string        `json:"trainer,omitempty"`
+	Session   string        `json:"session,omitempty"`
+	Seed      int64         `json:"seed,omitempty"`
+	TurnTimer int           `json:"turn_timer,omitempty"`
+	Team      []battlerSpec `json:"team,omitempty"`
+	Turn      int           `json:"turn,omitempty"`
+	Move      int           `json:"move"`
+	Check     string        `json:"check,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type lanConn struct {
+	conn net.Conn
+	enc  *json.Encoder
+	// msgs is closed when the connection drops.
+	msgs chan lanMessage
+	done chan struct{}
+}
+
+func newLANConn(conn net.Conn) *lanConn {
+	lc := &lanConn{conn: conn, enc: json.NewEncoder(conn), msgs: make(chan lanMessage), done: make(chan struct{})}
+	go func() {
+		defer close(lc.msgs)
+		dec := json.NewDecoder(conn)
+		for {
+			m := lanMessage{}
+			if err := dec.Decode(&m); err != nil {
+				return
+			}
+			select {
+			case lc.msgs <- m:
+			case <-lc.done:
+				return
+			}
+		}
+	}()
+	return lc
+}
+
+func (lc *lanConn) send(m lanMessage) error {
+	lc.conn.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+	return lc.enc.Encode(m)
+}
+
+// receive waits for the next message, giving up after timeout.
+func (lc *lanConn) receive(timeout time.Duration) (lanMessage, error) {
+	select {
+	case m, ok := <-lc.msgs:
+		if !ok {
+			return m, errors.New("the connection was closed")
+		}
+		if m.Type == "error" {
+			return m, fmt.Errorf("the other trainer's game said: %s", m.Error)
+		}
+		return m, nil
+	case <-time.After(timeout):
+		return lanMessage{}, errors.New("timed out waiting for the other trainer")
+	}
+}
+
+func (lc *lanConn) close() {
+	select {
+	case <-lc.done:
+	default:
+		close(lc.done)
+	}
+	lc.conn.Close()
+}
+
+type lanSide struct {
+	trainer string
+	team    []*battler
+	active  int
+}
+
+func (s *lanSide) current() *battler {
+	return s.team[s.active]
+}
+
+// sendNext moves on to the next pokemon that can still battle, reporting
+// false when there are none left.
+func (s *lanSide) sendNext() bool {
+	for s.active < len(s.team) && s.team[s.active].HP == 0 {
+		s.active++
+	}
+	if s.active == len(s.team) {
+		s.active = len(s.team) - 1
+		return false
+	}
+	return true
+}
+
+// lanBattle is a battle between two Pokedexes. Both sides run the same
+// engine with the same seed and only exchange their move choices, so each
+// turn plays out identically on both screens; the checksums catch it when
+// it doesn't.
+type lanBattle struct {
+	c         *config
+	session   string
+	me        int // 0 for the host, 1 for the guest
+	sides     [2]*lanSide
+	rng       *rand.Rand
+	turnTimer time.Duration
+	turn      int
+	conn      *lanConn
+	// connected is false between losing the connection and getting it back.
+	connected bool
+	// incoming delivers new connections to the host and resumed those of
+	// them that turned out to be the guest coming back; addr is where the
+	// guest redials.
+	incoming <-chan net.Conn
+	resumed  chan *lanConn
+	addr     string
+	// stop is closed when the battle is over, so nothing is left waiting to
+	// hand it a connection.
+	stop     chan struct{}
+	sent     map[int]lanMessage
+	received map[int]lanMessage
+	ended    *lanMessage
+}
+
+func partySpecs(c *config) ([]battlerSpec, error) {
+	if len(c.Party) == 0 {
+		return nil, fmt.Errorf("your party is empty, catch a pokemon first")
+	}
+	specs := []battlerSpec{}
+	for _, name := range c.Party {
+		p := c.Pokedex[name]
+		specs = append(specs, battlerSpec{Name: p.Name, Level: p.Level, IVs: p.IVs, EVs: p.EVs, Nature: p.Nature, Moves: p.KnownMoves})
+	}
+	return specs, nil
+}
+
+// lanTeam builds battlers from a team description, which may have come from
+// the other side and so is checked first.
+func lanTeam(c *config, specs []battlerSpec, foe bool) ([]*battler, error) {
+	if len(specs) == 0 || len(specs) > maxPartySize {
+		return nil, fmt.Errorf("a team must have 1 to %d pokemon", maxPartySize)
+	}
+	team := []*battler{}
+	for _, spec := range specs {
+		if spec.Level < 1 || spec.Level > maxLevel || len(spec.Moves) > 4 {
+			return nil, fmt.Errorf("%s is not a valid battler", spec.Name)
+		}
+		if _, ok := natures[spec.Nature]; !ok && spec.Nature != "" {
+			return nil, fmt.Errorf("%s has an unknown nature %s", spec.Name, spec.Nature)
+		}
+		if err := checkSpreads(spec.IVs, spec.EVs); err != nil {
+			return nil, fmt.Errorf("%s is not a valid battler: %v", spec.Name, err)
+		}
+		pokemon, err := fetchPokemon(c, spec.Name)
+		if err != nil {
+			return nil, err
+		}
+		// The foe's moves come from the other side, which could be sending
+		// anything.
+		if foe {
+			if err := checkLearnable(pokemon, spec.Moves); err != nil {
+				return nil, fmt.Errorf("%s is not a valid battler: %v", spec.Name, err)
+			}
+		}
+		stats := calcStats(pokemon, spec.Level, spec.IVs, spec.EVs, spec.Nature)
+		if stats["hp"] < 1 {
+			return nil, fmt.Errorf("%s is not a valid battler", spec.Name)
+		}
+		b := &battler{Name: spec.Name, Level: spec.Level, Types: typeNames(pokemon), Stats: stats, HP: stats["hp"]}
+		if foe {
+			b.Name = "the foe's " + spec.Name
+		}
+		moves := spec.Moves
+		if len(moves) == 0 {
+			moves = []string{"struggle"}
+		}
+		for _, name := range moves {
+			move, err := fetchMove(c, name)
+			if err != nil {
+				return nil, err
+			}
+			b.Moves = append(b.Moves, move)
+		}
+		team = append(team, b)
+	}
+	return team, nil
+}
+
+func newLANBattle(c *config, me int, session string, seed int64, turnTimer time.Duration, teams [2][]battlerSpec, trainers [2]string) (*lanBattle, error) {
+	b := &lanBattle{
+		c:         c,
+		session:   session,
+		me:        me,
+		rng:       rand.New(rand.NewSource(seed)),
+		turnTimer: turnTimer,
+		turn:      1,
+		connected: true,
+		sent:      make(map[int]lanMessage),
+		received:  make(map[int]lanMessage),
+	}
+	for i := range b.sides {
+		team, err := lanTeam(c, teams[i], i != me)
+		if err != nil {
+			return nil, err
+		}
+		b.sides[i] = &lanSide{trainer: trainers[i], team: team}
+	}
+	return b, nil
+}
+
+// hostLANBattle waits on ln for a guest and sets up the battle with it.
+// Typing cancel stops waiting.
+func hostLANBattle(c *config, ln net.Listener, turnTimer time.Duration) (*lanBattle, error) {
+	team, err := partySpecs(c)
+	if err != nil {
+		return nil, err
+	}
+	incoming, stop := make(chan net.Conn), make(chan struct{})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				close(incoming)
+				return
+			}
+			select {
+			case incoming <- conn:
+			case <-stop:
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		var conn net.Conn
+		select {
+		case line, ok := <-c.Input:
+			if !ok || strings.Join(CleanInput(line), " ") == "cancel" {
+				close(stop)
+				return nil, fmt.Errorf("stopped waiting for a challenger")
+			}
+			fmt.Fprintln(c.Out, "Still waiting, type cancel to stop.")
+			continue
+		case accepted, ok := <-incoming:
+			if !ok {
+				close(stop)
+				return nil, fmt.Errorf("stopped listening for challengers")
+			}
+			conn = accepted
+		}
+		lc := newLANConn(conn)
+		hello, err := lc.receive(handshakeTimeout)
+		if err == nil && hello.Type != "hello" {
+			err = fmt.Errorf("expected hello but got %s", hello.Type)
+		}
+		if err == nil && hello.Version != lanProtocolVersion {
+			err = fmt.Errorf("the challenger speaks battle protocol %d but this Pokedex speaks %d", hello.Version, lanProtocolVersion)
+		}
+		var b *lanBattle
+		if err == nil {
+			seed := rand.Int63()
+			session := newTradeID()
+			b, err = newLANBattle(c, 0, session, seed, turnTimer, [2][]battlerSpec{team, hello.Team}, [2]string{myTrainerID(c), hello.Trainer})
+			if err == nil {
+				err = lc.send(lanMessage{Type: "start", Version: lanProtocolVersion, Trainer: myTrainerID(c), Session: session, Seed: seed, TurnTimer: int(turnTimer / time.Second), Team: team})
+			}
+		}
+		if err != nil {
+			lc.send(lanMessage{Type: "error", Error: err.Error()})
+			lc.close()
+			fmt.Fprintln(c.Out, "Turned away a challenger:", err)
+			continue
+		}
+		b.conn, b.incoming, b.resumed, b.stop = lc, incoming, make(chan *lanConn), stop
+		return b, nil
+	}
+}
+
+// joinLANBattle connects to a host and sets up the battle with it.
+func joinLANBattle(c *config, addr string) (*lanBattle, error) {
+	team, err := partySpecs(c)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", addr, handshakeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+	lc := newLANConn(conn)
+	if err := lc.send(lanMessage{Type: "hello", Version: lanProtocolVersion, Trainer: myTrainerID(c), Team: team}); err != nil {
+		lc.close()
+		return nil, fmt.Errorf("failed to greet %s: %v", addr, err)
+	}
+	start, err := lc.receive(handshakeTimeout)
+	if err == nil && (start.Type != "start" || start.Version != lanProtocolVersion) {
+		err = fmt.Errorf("the host speaks battle protocol %d but this Pokedex speaks %d", start.Version, lanProtocolVersion)
+	}
+	var b *lanBattle
+	if err == nil {
+		b, err = newLANBattle(c, 1, start.Session, start.Seed, time.Duration(start.TurnTimer)*time.Second, [2][]battlerSpec{start.Team, team}, [2]string{start.Trainer, myTrainerID(c)})
+	}
+	if err != nil {
+		lc.close()
+		return nil, err
+	}
+	b.conn, b.addr = lc, addr
+	return b, nil
+}
+
+// check is a checksum of everything the battle engine has decided so far.
+func (b *lanBattle) check() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d", b.turn)
+	for _, side := range b.sides {
+		fmt.Fprintf(h, "|%d", side.active)
+		for _, member := range side.team {
+			fmt.Fprintf(h, ",%d", member.HP)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func (b *lanBattle) send(m lanMessage) {
+	if b.connected && b.conn.send(m) != nil {
+		b.disconnected()
+	}
+}
+
+func (b *lanBattle) disconnected() {
+	if b.connected {
+		fmt.Fprintln(b.c.Out, "Lost the connection to the other trainer...")
+		b.connected = false
+		b.conn.close()
+	}
+}
+
+// handle deals with a message from the other side while waiting for
+// something else.
+func (b *lanBattle) handle(m lanMessage, ok bool) error {
+	if !ok {
+		b.disconnected()
+		return nil
+	}
+	switch m.Type {
+	case "move":
+		if m.Turn >= b.turn {
+			b.received[m.Turn] = m
+		}
+	case "end":
+		b.ended = &m
+	case "error":
+		return fmt.Errorf("the other trainer's game said: %s", m.Error)
+	}
+	return nil
+}
+
+// msgs is the channel of messages from the other side, or nil while there
+// is no connection so that selecting on it blocks.
+func (b *lanBattle) msgs() chan lanMessage {
+	if !b.connected {
+		return nil
+	}
+	return b.conn.msgs
+}
+
+// accept checks a new connection to the host on the side, so a connection
+// that never says anything doesn't hold up the battle.
+func (b *lanBattle) accept(conn net.Conn, ok bool) {
+	if !ok {
+		b.incoming = nil
+		return
+	}
+	go b.resume(conn, b.turn)
+}
+
+// resume checks that a new connection to the host is the guest coming back
+// and hands it to the battle.
+func (b *lanBattle) resume(conn net.Conn, turn int) {
+	lc := newLANConn(conn)
+	m, err := lc.receive(handshakeTimeout)
+	if err == nil && (m.Type != "resume" || m.Session != b.session) {
+		err = errors.New("a battle is already in progress")
+	}
+	if err == nil {
+		err = lc.send(lanMessage{Type: "resumed", Session: b.session, Turn: turn})
+	}
+	if err != nil {
+		lc.send(lanMessage{Type: "error", Error: err.Error()})
+		lc.close()
+		return
+	}
+	select {
+	case b.resumed <- lc:
+	case <-b.stop:
+		lc.close()
+	}
+}
+
+// switchTo carries on the battle over a connection the guest resumed on.
+func (b *lanBattle) switchTo(lc *lanConn) {
+	if b.connected {
+		b.conn.close()
+	}
+	b.conn, b.connected = lc, true
+	fmt.Fprintln(b.c.Out, "The other trainer is back!")
+	b.resend()
+}
+
+// redial tries to get the guest's connection back until deadline.
+func (b *lanBattle) redial(deadline time.Time) {
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", b.addr, handshakeTimeout)
+		if err == nil {
+			lc := newLANConn(conn)
+			err = lc.send(lanMessage{Type: "resume", Session: b.session, Turn: b.turn})
+			if err == nil {
+				_, err = lc.receive(handshakeTimeout)
+			}
+			if err == nil {
+				b.conn, b.connected = lc, true
+				fmt.Fprintln(b.c.Out, "Reconnected!")
+				b.resend()
+				return
+			}
+			lc.close()
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// resend repeats the moves the other side may have missed while the
+// connection was down. Moves it already has are ignored.
+func (b *lanBattle) resend() {
+	for _, turn := range []int{b.turn - 1, b.turn} {
+		if m, ok := b.sent[turn]; ok {
+			b.send(m)
+		}
+	}
+}
+
+// reconnect waits up to the reconnect window for the connection to come
+// back: the host listens for the guest and the guest redials the host.
+func (b *lanBattle) reconnect() error {
+	deadline := time.Now().Add(reconnectWindow)
+	fmt.Fprintf(b.c.Out, "Waiting up to %s for the other trainer to reconnect...\n", reconnectWindow)
+	if b.incoming == nil {
+		b.redial(deadline)
+	}
+	for !b.connected && time.Now().Before(deadline) {
+		select {
+		case conn, ok := <-b.incoming:
+			if !ok {
+				return errors.New("stopped listening for the other trainer")
+			}
+			b.accept(conn, ok)
+		case lc := <-b.resumed:
+			b.switchTo(lc)
+		case <-time.After(time.Until(deadline)):
+		}
+	}
+	if !b.connected {
+		return errors.New("the other trainer didn't come back, the battle is abandoned")
+	}
+	return nil
+}
+
+// chooseMove asks the local player for a move, picking one at random when
+// the turn timer runs out.
+func (b *lanBattle) chooseMove() (int, error) {
+	mine := b.sides[b.me].current()
+	deadline := time.Now().Add(b.turnTimer)
+	for i, move := range mine.Moves {
+		fmt.Fprintf(b.c.Out, "  %d: %s\n", i+1, move.Name)
+	}
+	fmt.Fprintf(b.c.Out, "What will %s do? (1-%d or forfeit, %ds): ", mine.Name, len(mine.Moves), int(b.turnTimer/time.Second))
+	for {
+		select {
+		case line, ok := <-b.c.Input:
+			answer := CleanInput(line)
+			if !ok || len(answer) == 1 && answer[0] == "forfeit" {
+				return forfeitMove, nil
+			}
+			if len(answer) == 1 {
+				if n, err := strconv.Atoi(answer[0]); err == nil && n >= 1 && n <= len(mine.Moves) {
+					return n - 1, nil
+				}
+			}
+			fmt.Fprintf(b.c.Out, "Please choose 1-%d or forfeit: ", len(mine.Moves))
+		case m, ok := <-b.msgs():
+			if err := b.handle(m, ok); err != nil {
+				return 0, err
+			}
+		case conn, ok := <-b.incoming:
+			b.accept(conn, ok)
+		case lc := <-b.resumed:
+			b.switchTo(lc)
+		case <-time.After(time.Until(deadline)):
+			n := rand.Intn(len(mine.Moves))
+			fmt.Fprintf(b.c.Out, "\nTime's up! %s will use %s.\n", mine.Name, mine.Moves[n].Name)
+			return n, nil
+		}
+	}
+}
+
+// waitForMove waits for the other side's move for this turn.
+func (b *lanBattle) waitForMove() (lanMessage, error) {
+	deadline := time.Now().Add(b.turnTimer + turnGrace)
+	fmt.Fprintln(b.c.Out, "Waiting for the other trainer...")
+	for {
+		if m, ok := b.received[b.turn]; ok {
+			return m, nil
+		}
+		if !b.connected {
+			if err := b.reconnect(); err != nil {
+				return lanMessage{}, err
+			}
+			deadline = time.Now().Add(b.turnTimer + turnGrace)
+			continue
+		}
+		select {
+		case m, ok := <-b.msgs():
+			if err := b.handle(m, ok); err != nil {
+				return lanMessage{}, err
+			}
+		case conn, ok := <-b.incoming:
+			b.accept(conn, ok)
+		case lc := <-b.resumed:
+			b.switchTo(lc)
+		case <-time.After(time.Until(deadline)):
+			b.disconnected()
+		}
+	}
+}
+
+// playTurn plays one turn and returns the index of the winning side once
+// the battle is decided, or -1 while it goes on.
+func (b *lanBattle) playTurn() (int, error) {
+	mine, theirs := b.sides[b.me], b.sides[1-b.me]
+	fmt.Fprintf(b.c.Out, "\nTurn %d: %s HP %d/%d | %s HP %d/%d\n", b.turn, mine.current().Name, mine.current().HP, mine.current().Stats["hp"], theirs.current().Name, theirs.current().HP, theirs.current().Stats["hp"])
+	choice, err := b.chooseMove()
+	if err != nil {
+		return -1, err
+	}
+	sent := lanMessage{Type: "move", Session: b.session, Turn: b.turn, Move: choice, Check: b.check()}
+	b.sent[b.turn] = sent
+	delete(b.sent, b.turn-2)
+	b.send(sent)
+	peer, err := b.waitForMove()
+	if err != nil {
+		return -1, err
+	}
+	if peer.Check != sent.Check {
+		b.send(lanMessage{Type: "error", Error: errDesync.Error()})
+		return -1, errDesync
+	}
+
+	var moves [2]int
+	moves[b.me], moves[1-b.me] = choice, peer.Move
+	for i, side := range b.sides {
+		if moves[i] == forfeitMove {
+			fmt.Fprintf(b.c.Out, "Trainer %s forfeited!\n", side.trainer)
+			return 1 - i, nil
+		}
+		if moves[i] < 0 || moves[i] >= len(side.current().Moves) {
+			b.send(lanMessage{Type: "error", Error: errDesync.Error()})
+			return -1, errDesync
+		}
+	}
+
+	// Always resolve from the host's side first so both sides draw from the
+	// shared random source in the same order.
+	host, guest := b.sides[0].current(), b.sides[1].current()
+	hostMove, guestMove := host.Moves[moves[0]], guest.Moves[moves[1]]
+	first, second := host, guest
+	firstMove, secondMove := hostMove, guestMove
+	if !movesFirst(b.rng, host, guest, hostMove, guestMove) {
+		first, second = guest, host
+		firstMove, secondMove = guestMove, hostMove
+	}
+	if err := useMove(b.c, b.rng, first, second, firstMove); err != nil {
+		return -1, err
+	}
+	if second.HP > 0 {
+		if err := useMove(b.c, b.rng, second, first, secondMove); err != nil {
+			return -1, err
+		}
+	}
+
+	b.turn++
+	for i, side := range b.sides {
+		if side.current().HP > 0 {
+			continue
+		}
+		fmt.Fprintf(b.c.Out, "%s fainted!\n", side.current().Name)
+		if !side.sendNext() {
+			return 1 - i, nil
+		}
+		fmt.Fprintf(b.c.Out, "Trainer %s sent out %s!\n", side.trainer, side.current().Name)
+	}
+	return -1, nil
+}
+
+// run plays the battle to the end and returns the index of the winning side.
+func (b *lanBattle) run() (int, error) {
+	fmt.Fprintf(b.c.Out, "Trainer %s challenges trainer %s!\n", b.sides[1].trainer, b.sides[0].trainer)
+	for {
+		winner, err := b.playTurn()
+		if err != nil {
+			return winner, err
+		}
+		if winner >= 0 {
+			return winner, b.confirmResult()
+		}
+	}
+}
+
+// confirmResult compares how the last turn ended on both sides, which no
+// later move checksum would otherwise cover. A side that has already gone
+// is taken at its word.
+func (b *lanBattle) confirmResult() error {
+	b.send(lanMessage{Type: "end", Session: b.session, Turn: b.turn, Check: b.check()})
+	deadline := time.After(handshakeTimeout)
+	for b.ended == nil && b.connected {
+		select {
+		case m, ok := <-b.msgs():
+			if err := b.handle(m, ok); err != nil {
+				return err
+			}
+		case <-deadline:
+			return nil
+		}
+	}
+	if b.ended != nil && b.ended.Check != b.check() {
+		return errDesync
+	}
+	return nil
+}
+
+func (b *lanBattle) close() {
+	if b.connected {
+		b.conn.close()
+	}
+	if b.stop != nil {
+		select {
+		case <-b.stop:
+		default:
+			close(b.stop)
+		}
+	}
+}
+
+func announceWinner(b *lanBattle, winner int) {
+	if winner == b.me {
+		fmt.Fprintln(b.c.Out, "You won the battle!")
+	} else {
+		fmt.Fprintln(b.c.Out, "You lost the battle.")
+	}
+}
+
+func commandHostBattle(c *config, args []string) error {
+	addr, turnTimer := defaultBattleAddr, defaultTurnTimer
+	for i := 1; i < len(args); i++ {
+		if i+1 == len(args) {
+			return fmt.Errorf("%s needs a value", args[i])
+		}
+		switch args[i] {
+		case "--addr":
+			addr = args[i+1]
+		case "--timer":
+			seconds, err := strconv.Atoi(args[i+1])
+			if err != nil || seconds < 1 {
+				return fmt.Errorf("--timer must be a number of seconds")
+			}
+			turnTimer = time.Duration(seconds) * time.Second
+		default:
+			return fmt.Errorf("unknown option: %s", args[i])
+		}
+		i++
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+	defer ln.Close()
+	fmt.Fprintf(c.Out, "Hosting a battle on %s, waiting for a challenger (type cancel to stop)...\n", ln.Addr())
+	b, err := hostLANBattle(c, ln, turnTimer)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+	winner, err := b.run()
+	if err != nil {
+		return err
+	}
+	announceWinner(b, winner)
+	return nil
+}
+
+func commandJoinBattle(c *config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: join-battle <addr>")
+	}
+	b, err := joinLANBattle(c, args[1])
+	if err != nil {
+		return err
+	}
+	defer b.close()
+	winner, err := b.run()
+	if err != nil {
+		return err
+	}
+	announceWinner(b, winner)
+	return nil
+}
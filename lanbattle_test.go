@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+// lanSpecies are the pokemon test trainers battle with, by base speed.
+var lanSpecies = map[string]int{"snorlax": 50, "rattata": 51, "raticate": 52}
+
+// testLANConfig is a trainer whose party knows only tackle, with the move,
+// its type and every test species already cached so battles need no
+// network.
+func testLANConfig(t *testing.T, party ...string) (*config, chan string) {
+	t.Helper()
+	cache := pokecache.NewCache(time.Hour)
+	cache.Add("https://pokeapi.co/api/v2/move/tackle", []byte(`{"name":"tackle","power":40,"accuracy":100,"type":{"name":"normal"},"damage_class":{"name":"physical"}}`))
+	cache.Add("https://pokeapi.co/api/v2/type/normal", []byte(`{"name":"normal","damage_relations":{}}`))
+	species := make(map[string]*CaughtPokemon)
+	for name, speed := range lanSpecies {
+		species[name] = testCaught(t, speed, name, 10, []string{"normal"}, map[string]int{"hp": 50, "attack": 100, "defense": 50, "speed": speed})
+		if err := json.Unmarshal([]byte(`{"moves":[{"move":{"name":"tackle"}}]}`), &species[name].Pokemon); err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(species[name].Pokemon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache.Add("https://pokeapi.co/api/v2/pokemon/"+name, data)
+	}
+	input := make(chan string, 100)
+	c := &config{Cache: cache, Input: input, Out: io.Discard, Pokedex: make(map[string]*CaughtPokemon), TrainerKey: newTrainerKey()}
+	for _, name := range party {
+		p := species[name]
+		p.Level = 20
+		p.KnownMoves = []string{"tackle"}
+		c.Pokedex[name] = p
+		c.Party = append(c.Party, name)
+	}
+	return c, input
+}
+
+func choose(input chan string, answers ...string) {
+	for _, answer := range answers {
+		input <- answer
+	}
+}
+
+func repeat(answer string, n int) []string {
+	answers := make([]string, n)
+	for i := range answers {
+		answers[i] = answer
+	}
+	return answers
+}
+
+type lanResult struct {
+	winner int
+	err    error
+}
+
+// startLANBattle sets up a battle between two trainers over loopback.
+func startLANBattle(t *testing.T, host, guest *config, turnTimer time.Duration) (*lanBattle, *lanBattle) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	hosted := make(chan *lanBattle)
+	go func() {
+		b, err := hostLANBattle(host, ln, turnTimer)
+		if err != nil {
+			t.Error(err)
+		}
+		hosted <- b
+	}()
+	joined, err := joinLANBattle(guest, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostBattle := <-hosted
+	if hostBattle == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() { hostBattle.close(); joined.close() })
+	return hostBattle, joined
+}
+
+func runBoth(host, guest *lanBattle) (lanResult, lanResult) {
+	results := make(chan lanResult)
+	go func() {
+		winner, err := host.run()
+		results <- lanResult{winner, err}
+	}()
+	winner, err := guest.run()
+	return <-results, lanResult{winner, err}
+}
+
+func TestLANBattle(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, guestInput := testLANConfig(t, "rattata", "raticate")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Minute)
+	choose(hostInput, repeat("1", 20)...)
+	choose(guestInput, append([]string{"9", "fly"}, repeat("1", 20)...)...)
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.err != nil || guestResult.err != nil {
+		t.Fatalf("unexpected errors: %v, %v", hostResult.err, guestResult.err)
+	}
+	if hostResult.winner != guestResult.winner || hostBattle.check() != guestBattle.check() {
+		t.Errorf("the sides disagree: host says %d won, guest says %d", hostResult.winner, guestResult.winner)
+	}
+}
+
+func TestLANBattleForfeit(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, guestInput := testLANConfig(t, "rattata")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Minute)
+	choose(hostInput, "1")
+	choose(guestInput, "forfeit")
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.winner != 0 || guestResult.winner != 0 {
+		t.Errorf("expected the host to win by forfeit, got %+v and %+v", hostResult, guestResult)
+	}
+}
+
+func TestLANBattleTurnTimer(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, _ := testLANConfig(t, "rattata")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Second)
+	choose(hostInput, repeat("1", 20)...)
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.err != nil || guestResult.err != nil || hostResult.winner != guestResult.winner {
+		t.Errorf("expected the timer to pick the guest's moves, got %+v and %+v", hostResult, guestResult)
+	}
+}
+
+func TestLANBattleDesync(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, guestInput := testLANConfig(t, "rattata")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Minute)
+	choose(hostInput, "1", "1")
+	choose(guestInput, "1", "1")
+	hostBattle.sides[1].current().HP--
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.err != errDesync || guestResult.err == nil {
+		t.Errorf("expected both sides to notice the desync, got %v and %v", hostResult.err, guestResult.err)
+	}
+}
+
+func TestLANBattleReconnect(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, guestInput := testLANConfig(t, "rattata")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Minute)
+	choose(hostInput, repeat("1", 20)...)
+	choose(guestInput, repeat("1", 20)...)
+	guestBattle.conn.conn.Close()
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.err != nil || guestResult.err != nil || hostResult.winner != guestResult.winner {
+		t.Errorf("expected the battle to carry on after reconnecting, got %+v and %+v", hostResult, guestResult)
+	}
+}
+
+func TestLANBattleVersionMismatch(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	hosted := make(chan error)
+	go func() {
+		_, err := hostLANBattle(host, ln, time.Minute)
+		hosted <- err
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	json.NewEncoder(conn).Encode(lanMessage{Type: "hello", Version: lanProtocolVersion + 1})
+	reply := lanMessage{}
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != "error" || !strings.Contains(reply.Error, "protocol") {
+		t.Errorf("expected a protocol error, got %+v", reply)
+	}
+
+	hostInput <- "cancel"
+	if err := <-hosted; err == nil {
+		t.Error("expected the host to stop waiting")
+	}
+}
+
+func TestLANTeamWorksOutStats(t *testing.T) {
+	c, _ := testLANConfig(t)
+	spec := battlerSpec{Name: "rattata", Level: 20, IVs: map[string]int{"attack": 31}, EVs: map[string]int{"attack": 252}, Nature: "adamant", Moves: []string{"tackle"}}
+	team, err := lanTeam(c, []battlerSpec{spec}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := calcStat("attack", 100, 31, 252, 20, "adamant"); team[0].Stats["attack"] != want || team[0].Types[0] != "normal" {
+		t.Errorf("expected attack %d from the species, got %v and types %v", want, team[0].Stats, team[0].Types)
+	}
+
+	cheats := []battlerSpec{
+		{Name: "rattata", Level: 101},
+		{Name: "rattata", Level: 20, IVs: map[string]int{"attack": 32}},
+		{Name: "rattata", Level: 20, EVs: map[string]int{"attack": 252, "speed": 252, "hp": 252}},
+		{Name: "rattata", Level: 20, Nature: "sneaky"},
+		{Name: "rattata", Level: 5, Moves: []string{"v-create"}},
+	}
+	for _, cheat := range cheats {
+		if _, err := lanTeam(c, []battlerSpec{cheat}, true); err == nil {
+			t.Errorf("expected %+v to be turned away", cheat)
+		}
+	}
+}
+
+func TestLANBattleIgnoresStrayConnections(t *testing.T) {
+	host, hostInput := testLANConfig(t, "snorlax")
+	guest, guestInput := testLANConfig(t, "rattata")
+	hostBattle, guestBattle := startLANBattle(t, host, guest, time.Minute)
+	// A connection that never says anything must not hold up the battle
+	// while it is checked.
+	stray, err := net.Dial("tcp", hostBattle.conn.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stray.Close()
+	choose(hostInput, repeat("1", 20)...)
+	choose(guestInput, repeat("1", 20)...)
+	start := time.Now()
+	hostResult, guestResult := runBoth(hostBattle, guestBattle)
+	if hostResult.err != nil || guestResult.err != nil {
+		t.Fatalf("unexpected errors: %v, %v", hostResult.err, guestResult.err)
+	}
+	if elapsed := time.Since(start); elapsed > handshakeTimeout/2 {
+		t.Errorf("the battle took %s, expected the stray connection not to slow it down", elapsed)
+	}
+
+	// Once the battle is over, late connections are turned away rather than
+	// left waiting for a battle that will never take them.
+	hostBattle.close()
+	late, err := net.Dial("tcp", hostBattle.conn.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+	late.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := late.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected a late connection to be closed, got %v", err)
+	}
+}
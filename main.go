@@ -6,9 +6,15 @@ import (
 	"os"
 	"net/http"
 	"encoding/json"
+	"io"
 	"github.com/glitchdawg/pokedex/internal/pokecache"
 	"time"
 	"math/rand"
+	"path/filepath"
+	"crypto/ed25519"
+	"errors"
+	"net"
+	"strconv"
 )
 
 
@@ -17,15 +23,67 @@ type config struct {
 	Next string
 	Previous interface{}
 	Cache *pokecache.Cache
-	Pokedex map[string]Pokemon
+	HTTP *http.Client
+	Pokedex map[string]*CaughtPokemon
+	Party []string
+	Version string
+	VersionGroup string
+	Area *ExploredLocation
+	Input <-chan string
+	Out io.Writer
+	Bag map[string]int
+	Money int
+	SavePath string
+	Settings Settings
+	ShinyHunt map[string]int
+	Seen map[string]bool
+	NextCaughtID int
+	TrainerKey ed25519.PrivateKey
+	PendingTrades map[string]*CaughtPokemon
+	AcceptedTrades map[string]bool
+	// OnCatch, if set, hears about every ball thrown at a pokemon; caught is
+	// nil when it got away.
+	OnCatch func(pokemon Pokemon, ball string, caught *CaughtPokemon)
 }
 type cliCommand struct {
 	name        string
 	description string
 	callback    func(*config,[]string) error
+	// keepCase passes arguments through as typed, for commands that take
+	// file paths or language codes. Everything else gets them lowercased.
+	keepCase    bool
 }
 
 
+// httpClient is the client PokeAPI requests go through. Sessions of a host
+// share one, so they share its connections too.
+func httpClient(c *config) *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// pokeAPIGet sends a GET request for url, recording how long the response
+// took to arrive by endpoint and status.
+func pokeAPIGet(c *config, url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	start := time.Now()
+	resp, err := httpClient(c).Do(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	pokeAPILatency.Observe(time.Since(start).Seconds(), endpointLabel(url), status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	return resp, nil
+}
+
 func fetchLocations(url string,c *config) (LocationStruct, error) {
 	locations := LocationStruct{}
 
@@ -36,14 +94,9 @@ func fetchLocations(url string,c *config) (LocationStruct, error) {
         }
 	}
 
-	res,err:=http.NewRequest("GET",url,nil)
+	resp, err := pokeAPIGet(c, url)
 	if err != nil {
-		return locations,fmt.Errorf("failed to create request: %v", err)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(res)
-	if err != nil {
-		return locations,fmt.Errorf("failed to send request: %v", err)
+		return locations, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -62,6 +115,61 @@ func fetchLocations(url string,c *config) (LocationStruct, error) {
 	return locations, nil
 }
 
+// fetchJSON decodes the PokeAPI resource at url into v, going through the
+// cache first the same way fetchLocations does.
+func fetchJSON(c *config, url string, v interface{}) error {
+	if cachedData, ok := c.Cache.Get(url); ok {
+		if err := json.Unmarshal(cachedData, v); err == nil {
+			return nil
+		}
+	}
+	resp, err := pokeAPIGet(c, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get response: %v", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	responseBytes, _ := json.Marshal(v)
+	c.Cache.Add(url, responseBytes)
+	return nil
+}
+
+// fetchBytes downloads a raw resource such as a sprite, going through the cache.
+func fetchBytes(c *config, url string) ([]byte, error) {
+	if cachedData, ok := c.Cache.Get(url); ok {
+		return cachedData, nil
+	}
+	data, err := downloadBytes(c, url)
+	if err != nil {
+		return nil, err
+	}
+	c.Cache.Add(url, data)
+	return data, nil
+}
+
+// downloadBytes downloads a raw resource, leaving it to the caller to cache
+// it.
+func downloadBytes(c *config, url string) ([]byte, error) {
+	resp, err := pokeAPIGet(c, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get response: %v", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	return data, nil
+}
+
 func CleanInput(text string) []string{
 	text = strings.ToLower(text)
 	text = strings.TrimSpace(text)
@@ -69,19 +177,100 @@ func CleanInput(text string) []string{
 	return words
 }
 
+// commandArgs splits text into the words command is called with. Only the
+// command name is lowercased for commands that keep case.
+func commandArgs(command cliCommand, text string) []string {
+	if !command.keepCase {
+		return CleanInput(text)
+	}
+	words := strings.Fields(text)
+	if len(words) > 0 {
+		words[0] = strings.ToLower(words[0])
+	}
+	return words
+}
+
+// prompt asks the user a question mid-command and returns the cleaned answer.
+// ok is false once the input has been exhausted.
+func prompt(c *config, question string) (answer string, ok bool) {
+	fmt.Fprint(c.Out, question)
+	if c.Input == nil {
+		fmt.Fprintln(c.Out)
+		return "", false
+	}
+	line, ok := <-c.Input
+	if !ok {
+		fmt.Fprintln(c.Out)
+		return "", false
+	}
+	return strings.Join(CleanInput(line), " "), true
+}
+
+// readLines feeds the lines of r to a channel, so a command can wait for
+// input and something else at once. The channel is closed at the end of the
+// input.
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		// A host closes a session's connection once the trainer exits, or
+		// takes too long to log in.
+		if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, os.ErrDeadlineExceeded) {
+			fmt.Println("Error reading input:", err)
+		}
+		close(lines)
+	}()
+	return lines
+}
+
+// errExit is returned by the exit command to end the session running it.
+var errExit = errors.New("exit")
+
 func commandExit(c *config, args []string) error{
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	os.Exit(0)
-	return nil
+	fmt.Fprintln(c.Out, "Closing the Pokedex... Goodbye!")
+	return errExit
 }
-func commandHelp(c *config, args []string) error{
-    fmt.Println(`Welcome to the Pokedex!
+// helpText has a line for every command, starting with its name.
+const helpText = `Welcome to the Pokedex!
 Usage:
 
 help: Displays a help message
 exit: Exit the Pokedex
 map: Display the names of 20 location areas in the Pokemon world
-mapb: Display the previous 20 location areas`)
+mapb: Display the previous 20 location areas
+battle <pokemon>: Battle a wild pokemon with the lead of your party
+party: Show your party (party add|remove|lead <name> to manage it)
+inspect <name> [--moves] [--sprite]: Show a caught pokemon, optionally with its full learnset and sprite
+sprite <name> [--shiny] [--back] [--gen <n>]: Draw a pokemon's sprite in the terminal
+cry <name> [--legacy]: Play a pokemon's cry
+shinyhunt [name]: Show encounters since the last shiny of each species
+pokedex [--type <type>] [--min-stat <stat>=<n>] [--sort id|name|weight|caught-at] [--reverse] [--limit <n>]: List your caught pokemon
+find <query>: Search caught pokemon, e.g. find type=water and speed>90 and not legendary order by attack desc
+compare <a> <b> [<c>...]: Compare the base stats, types and abilities of pokemon side by side
+stats <caught-id|name>: Show the actual stats of a caught pokemon with its IVs, EVs and nature
+statcalc <pokemon> [--level <n>] [--nature <name>] [--ivs a/b/c/d/e/f] [--evs a/b/c/d/e/f]: Calculate stats
+coverage [names...]: Show the type weaknesses and move coverage of your party or the named pokemon
+suggest-team [--must-include <name>] [--no-legendaries]: Suggest the best team of six from your caught pokemon
+export <file> [--format csv|md|html]: Export caught pokemon to a spreadsheet, markdown table or web page
+export-showdown [file]: Write your party as a Pokemon Showdown team
+import-showdown <file>: Add the pokemon in a Pokemon Showdown team to your Pokedex
+trade [offer <caught-id> <trainer-key> [file] | accept <code|file> [file] | confirm <code|file> [file] | receive <code|file> | cancel <trade-id>]: Trade pokemon with another trainer
+host-battle [--addr :7777] [--timer <seconds>]: Host a battle against another trainer's party on the network
+join-battle <addr>: Join a battle hosted by another trainer
+progress [national|generation|region|type]: Show how much of the Pokedex you have completed
+settings [<name> <value>]: Show or change your settings
+move <name>: Show the details of a move
+catch <pokemon> [ball]: Throw a ball from your bag at a pokemon
+bag: Show your money and items
+mart [buy <item> [quantity]]: Buy Poke Balls and potions
+ability <name> [--lang <code>]: Show what an ability does and which pokemon can have it
+version [name]: Show or change the game version used for encounters and moves`
+
+func commandHelp(c *config, args []string) error{
+    fmt.Fprintln(c.Out, helpText)
     return nil
 }
 func commandMap(c *config, args []string) error{
@@ -96,7 +285,7 @@ func commandMap(c *config, args []string) error{
 		return fmt.Errorf("failed to fetch locations: %v", err)
 	}
 	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+		fmt.Fprintln(c.Out, location.Name)
 	}
 	c.Next = locations.Next
 	c.Previous = locations.Previous
@@ -104,7 +293,7 @@ func commandMap(c *config, args []string) error{
 }
 func commandMapb(c *config, args []string) error{
 	if c.Previous == nil {
-        fmt.Println("you're on the first page")
+        fmt.Fprintln(c.Out, "you're on the first page")
         return nil
     }
 	prevURL, ok := c.Previous.(string)
@@ -118,17 +307,18 @@ func commandMapb(c *config, args []string) error{
 		return fmt.Errorf("failed to fetch locations: %v", err)
 	}
 	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+		fmt.Fprintln(c.Out, location.Name)
 	}
 	c.Next = locations.Next
 	c.Previous = locations.Previous
 	return nil
 }
-func traverseLocations(locationData ExploredLocation,area string) error{
-	fmt.Printf("Exploring %s...\n", area)
-	fmt.Println("Found Pokemon:")
+func traverseLocations(c *config,locationData ExploredLocation,area string) error{
+	fmt.Fprintf(c.Out, "Exploring %s...\n", area)
+	fmt.Fprintln(c.Out, "Found Pokemon:")
 	for _, encounter := range locationData.PokemonEncounters {
-		fmt.Printf("- %s\n", encounter.Pokemon.Name)
+		fmt.Fprintf(c.Out, "- %s\n", encounter.Pokemon.Name)
+		markSeen(c, encounter.Pokemon.Name)
 	}
 	return nil
 }
@@ -149,21 +339,18 @@ func commandExplore(c *config, args []string) error{
 	if cachedData, ok := c.Cache.Get(url); ok {
 		err := json.Unmarshal(cachedData, &locationData)
 		if err == nil {
-			err=traverseLocations(locationData, area)
+			c.Area = &locationData
+			err=traverseLocations(c, locationData, area)
 			if err != nil {
 				return fmt.Errorf("failed to traverse locations: %v", err)
 			}
+			rewardExploring(c)
 			return nil
 		}
 	}
-	res, err := http.NewRequest("GET", url, nil)
+	resp, err := pokeAPIGet(c, url)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(res)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		return err
 	}
 
 	defer resp.Body.Close()
@@ -175,59 +362,104 @@ func commandExplore(c *config, args []string) error{
 	if err != nil {
 		return fmt.Errorf("failed to decode response: %v", err)
 	}
-	err=traverseLocations(locationData, area)
+	c.Area = &locationData
+	err=traverseLocations(c, locationData, area)
 	if err != nil {
 			return fmt.Errorf("failed to traverse locations: %v", err)
 	}
 	responseBytes, _ := json.Marshal(locationData)
 	c.Cache.Add(url, responseBytes)
+	rewardExploring(c)
 	return nil
 }
 
-func catchPokemon(c *config,pokemon Pokemon) error {
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemon.Name)
+// catchPokemon throws a ball at a pokemon and returns the caught record, or
+// nil when it got away.
+func catchPokemon(c *config,pokemon Pokemon,ball string) (*CaughtPokemon, error) {
+	if !useItem(c, ball) {
+		return nil, fmt.Errorf("you have no %s left, buy more at the mart", ball)
+	}
+	shiny := encounterShiny(c, pokemon.Species.Name)
+	fmt.Fprintf(c.Out, "Throwing a %s at %s...\n", ball, pokemon.Name)
 	rand.Seed(time.Now().UnixNano())
 	catchChance := rand.Float64()
 	catchRate := 1.0 - (float64(pokemon.BaseExperience) / 1000.0)
+	catchRate *= ballBonus[ball]
 	if catchChance < catchRate {
-		fmt.Printf("%s was caught!\n", pokemon.Name)
-		c.Pokedex[pokemon.Name] = pokemon
-	} else {
-		fmt.Printf("%s excaped!\n", pokemon.Name)
+		fmt.Fprintf(c.Out, "%s was caught!\n", pokemon.Name)
+		caught, err := newCaughtPokemon(c, pokemon, wildLevel(c, pokemon.Name))
+		if err != nil {
+			return nil, err
+		}
+		caught.Shiny = shiny
+		if caught.HeldItem != "" {
+			fmt.Fprintf(c.Out, "%s was holding %s.\n", pokemon.Name, caught.HeldItem)
+		}
+		if !confirmReplace(c, caught.Name) {
+			fmt.Fprintf(c.Out, "You kept your %s and let the new one go.\n", caught.Name)
+			return nil, nil
+		}
+		addCaught(c, caught)
+		// Experience comes after the catch is kept, so a lead replaced by the
+		// new pokemon doesn't take it away with it.
+		if lead := partyLead(c); lead != nil {
+			err = gainExperience(c, lead, experienceYield(pokemon.BaseExperience, caught.Level))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if c.OnCatch != nil {
+			c.OnCatch(pokemon, ball, caught)
+		}
+		if c.Settings.CryOnCatch {
+			if err := playCry(c, pokemon, false); err != nil {
+				fmt.Fprintln(c.Out, "Could not play cry:", err)
+			}
+		}
+		return caught, nil
 	}
-	return nil
+	fmt.Fprintf(c.Out, "%s excaped!\n", pokemon.Name)
+	if c.OnCatch != nil {
+		c.OnCatch(pokemon, ball, nil)
+	}
+	return nil, nil
 }
 func commandCatch(c *config, args []string) error{
 	if len(args) < 2 {
 		return fmt.Errorf("please provide a pokemon name")
 	}
-	if len(args) > 2 {
-		return fmt.Errorf("too many arguments, please provide only one pokemon name")
+	if len(args) > 3 {
+		return fmt.Errorf("too many arguments, please provide a pokemon name and optionally a ball")
 	}
 	if args[1] == "" {
 		return fmt.Errorf("please provide a valid pokemon name")
 	}
+	ball := "poke-ball"
+	if len(args) == 3 {
+		ball = args[2]
+	}
+	if _, ok := ballBonus[ball]; !ok {
+		return fmt.Errorf("%s is not a kind of ball", ball)
+	}
+	if c.Bag[ball] == 0 {
+		return fmt.Errorf("you have no %s left, buy more at the mart", ball)
+	}
 	pokemonName := args[1]
 	pokemonInfo := Pokemon{}
 	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", pokemonName)
 	if cachedData, ok := c.Cache.Get(url); ok {
 		err := json.Unmarshal(cachedData, &pokemonInfo)
 		if err == nil {
-			err=catchPokemon(c,pokemonInfo)
+			_, err=catchPokemon(c,pokemonInfo,ball)
 			if err != nil {
 				return fmt.Errorf("failed to catch pokemon: %v", err)
 			}
 			return nil
 		}
 	}
-	res, err := http.NewRequest("GET", url, nil)
+	resp, err := pokeAPIGet(c, url)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(res)	
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -238,7 +470,7 @@ func commandCatch(c *config, args []string) error{
 	if err != nil {
 		return fmt.Errorf("failed to decode response: %v", err)
 	}
-	err=catchPokemon(c,pokemonInfo)
+	_, err=catchPokemon(c,pokemonInfo,ball)
 	if err != nil {
 		return fmt.Errorf("failed to catch pokemon: %v", err)
 	}
@@ -251,25 +483,59 @@ func commandInspect(c *config, args []string) error{
 	if len(args) < 2 {
 		return fmt.Errorf("please provide a pokemon name")
 	}
-	if len(args) > 2 {
-		return fmt.Errorf("too many arguments, please provide only one pokemon name")
+	showMoves, showSprite := false, false
+	for _, flag := range args[2:] {
+		switch flag {
+		case "--moves":
+			showMoves = true
+		case "--sprite":
+			showSprite = true
+		default:
+			return fmt.Errorf("too many arguments, please provide only one pokemon name")
+		}
 	}
 	if args[1] == "" {
 		return fmt.Errorf("please provide a valid pokemon name")
 	}
 	pokemonName := args[1]
 	if pokemonInfo,ok:= c.Pokedex[pokemonName]; ok {
-		fmt.Printf("Name: %s\n", pokemonInfo.Name)
-		fmt.Printf("Height: %d\n", pokemonInfo.Height)
-		fmt.Printf("Weight: %d\n", pokemonInfo.Weight)
-		fmt.Printf("Stats:\n")
+		if showSprite {
+			url := pokemonInfo.Sprites.FrontDefault
+			if pokemonInfo.Shiny {
+				url = pokemonInfo.Sprites.FrontShiny
+			}
+			art, err := drawImage(c, url, inspectSpriteCols, inspectSpriteRows)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(c.Out, art)
+		}
+		fmt.Fprintf(c.Out, "Name: %s\n", pokemonInfo.Name)
+		fmt.Fprintf(c.Out, "Caught ID: %d\n", pokemonInfo.CaughtID)
+		if pokemonInfo.Shiny {
+			fmt.Fprintln(c.Out, "Shiny: yes")
+		}
+		fmt.Fprintf(c.Out, "Level: %d\n", pokemonInfo.Level)
+		fmt.Fprintf(c.Out, "Experience: %d\n", pokemonInfo.Experience)
+		fmt.Fprintf(c.Out, "Height: %d\n", pokemonInfo.Height)
+		fmt.Fprintf(c.Out, "Weight: %d\n", pokemonInfo.Weight)
+		fmt.Fprintf(c.Out, "Stats:\n")
 		for _, stat := range pokemonInfo.Stats {
-			fmt.Printf("  %s: %d\n", stat.Stat.Name, stat.BaseStat)
+			fmt.Fprintf(c.Out, "  %s: %d\n", stat.Stat.Name, stat.BaseStat)
 		}
-		fmt.Printf("Types:\n")
+		fmt.Fprintf(c.Out, "Types:\n")
 		for _, t := range pokemonInfo.Types {
-			fmt.Printf("  %s\n", t.Type.Name)
+			fmt.Fprintf(c.Out, "  %s\n", t.Type.Name)
 		}		
+		printAbilities(c.Out, pokemonInfo)
+		printHeldItems(c, pokemonInfo)
+		fmt.Fprintf(c.Out, "Moves:\n")
+		for _, move := range pokemonInfo.KnownMoves {
+			fmt.Fprintf(c.Out, "  %s\n", move)
+		}
+		if showMoves {
+			printLearnset(c.Out, pokemonInfo.Pokemon, c.VersionGroup)
+		}
 
 	} else {
 		return fmt.Errorf("pokemon not found in your Pokedex")
@@ -279,24 +545,63 @@ func commandInspect(c *config, args []string) error{
 
 func commandPokedex(c *config, args []string) error{
 	if len(c.Pokedex) == 0 {
-		fmt.Println("Your Pokedex is empty.")
+		fmt.Fprintln(c.Out, "Your Pokedex is empty.")
 		return nil
 	}
-	fmt.Println("Your Pokedex:")
-	for name, _ := range c.Pokedex {
-		fmt.Printf("- %s\n", name)
+	query, err := parsePokedexQuery(args[1:])
+	if err != nil {
+		return err
+	}
+	results := query.apply(c.Pokedex)
+	if len(results) == 0 {
+		fmt.Fprintln(c.Out, "No pokemon in your Pokedex match.")
+		return nil
+	}
+	fmt.Fprintln(c.Out, "Your Pokedex:")
+	for _, pokemon := range results {
+		if pokemon.Shiny {
+			fmt.Fprintf(c.Out, "- [%d] #%03d %s (Lv. %d, shiny)\n", pokemon.CaughtID, pokemon.ID, pokemon.Name, pokemon.Level)
+		} else {
+			fmt.Fprintf(c.Out, "- [%d] #%03d %s (Lv. %d)\n", pokemon.CaughtID, pokemon.ID, pokemon.Name, pokemon.Level)
+		}
 	}
 	return nil
 }
 
-func main() {
-	cache:=pokecache.NewCache(5*time.Minute)
-	scanner := bufio.NewScanner(os.Stdin)
-	cfg := &config{
-		Cache: cache,
-		Pokedex: make(map[string]Pokemon),
+// repl runs commands read from c.Input until the input ends or the trainer
+// exits, saving after every command.
+func repl(c *config, commands map[string]cliCommand) {
+	for {
+		fmt.Fprint(c.Out, "Pokedex > ")
+		text, ok := <-c.Input
+		if !ok {
+			return
+		}
+		words := CleanInput(text)
+		if len(words) == 0 {
+			continue
+		}
+		command := commands[words[0]]
+		if command.name == "" {
+			fmt.Fprintln(c.Out, "Unknown command:", words[0])
+			continue
+		}
+		err := command.callback(c, commandArgs(command, text))
+		if err != nil && !errors.Is(err, errExit) {
+			fmt.Fprintln(c.Out, "Error executing command:", err)
+		}
+		if err := saveGame(c); err != nil {
+			fmt.Fprintln(c.Out, "Error saving game:", err)
+		}
+		if errors.Is(err, errExit) {
+			return
+		}
 	}
-	commands:=map[string]cliCommand{
+}
+
+// cliCommands is every command the REPL understands, by name.
+func cliCommands() map[string]cliCommand {
+	return map[string]cliCommand{
 		"exit": {
 			name:        "exit",
 			description: "Exit the Pokedex",
@@ -337,29 +642,160 @@ func main() {
 			description: "Show the pokedex",
 			callback: commandPokedex,
 		},
+		"battle":{
+			name: "battle",
+			description: "Battle a wild pokemon",
+			callback: commandBattle,
+		},
+		"party":{
+			name: "party",
+			description: "Show and manage your party",
+			callback: commandParty,
+		},
+		"move":{
+			name: "move",
+			description: "Show the details of a move",
+			callback: commandMove,
+		},
+		"ability":{
+			name: "ability",
+			description: "Show the details of an ability",
+			callback: commandAbility,
+			keepCase: true,
+		},
+		"bag":{
+			name: "bag",
+			description: "Show your bag",
+			callback: commandBag,
+		},
+		"mart":{
+			name: "mart",
+			description: "Buy items at the Poke Mart",
+			callback: commandMart,
+		},
+		"sprite":{
+			name: "sprite",
+			description: "Draw a pokemon's sprite",
+			callback: commandSprite,
+		},
+		"cry":{
+			name: "cry",
+			description: "Play a pokemon's cry",
+			callback: commandCry,
+		},
+		"shinyhunt":{
+			name: "shinyhunt",
+			description: "Show shiny hunt counters",
+			callback: commandShinyHunt,
+		},
+		"find":{
+			name: "find",
+			description: "Search caught pokemon with a query",
+			callback: commandFind,
+		},
+		"compare":{
+			name: "compare",
+			description: "Compare pokemon side by side",
+			callback: commandCompare,
+		},
+		"stats":{
+			name: "stats",
+			description: "Show the actual stats of a caught pokemon",
+			callback: commandStats,
+		},
+		"statcalc":{
+			name: "statcalc",
+			description: "Calculate stats from IVs, EVs, nature and level",
+			callback: commandStatCalc,
+		},
+		"coverage":{
+			name: "coverage",
+			description: "Analyze type coverage of a team",
+			callback: commandCoverage,
+		},
+		"suggest-team":{
+			name: "suggest-team",
+			description: "Suggest a team from caught pokemon",
+			callback: commandSuggestTeam,
+		},
+		"export":{
+			name: "export",
+			description: "Export caught pokemon to a file",
+			callback: commandExport,
+			keepCase: true,
+		},
+		"export-showdown":{
+			name: "export-showdown",
+			description: "Export the party for Pokemon Showdown",
+			callback: commandExportShowdown,
+			keepCase: true,
+		},
+		"import-showdown":{
+			name: "import-showdown",
+			description: "Import a Pokemon Showdown team",
+			callback: commandImportShowdown,
+			keepCase: true,
+		},
+		"trade":{
+			name: "trade",
+			description: "Trade pokemon with another trainer",
+			callback: commandTrade,
+			keepCase: true,
+		},
+		"host-battle":{
+			name: "host-battle",
+			description: "Host a network battle",
+			callback: commandHostBattle,
+		},
+		"join-battle":{
+			name: "join-battle",
+			description: "Join a network battle",
+			callback: commandJoinBattle,
+		},
+		"progress":{
+			name: "progress",
+			description: "Show Pokedex completion",
+			callback: commandProgress,
+		},
+		"settings":{
+			name: "settings",
+			description: "Show or change settings",
+			callback: commandSettings,
+			keepCase: true,
+		},
+		"version":{
+			name: "version",
+			description: "Show or change the game version",
+			callback: commandVersion,
+		},
 
 	}
-	
-	for {
-		fmt.Print("Pokedex > ")
-		if scanner.Scan() {
-			text := scanner.Text()
-			words := CleanInput(text)
-			if len(words) == 0 {
-				continue
-			}
-			if command:= commands[words[0]]; command.name != "" {
-				
-				if err := command.callback(cfg,words); err != nil {
-					fmt.Println("Error executing command:", err)
-				}
-			} else {
-				fmt.Println("Unknown command:", words[0])
-			}
-		}
-	
-		if err := scanner.Err(); err != nil {
-			fmt.Println("Error reading input:", err)
+}
+
+func main() {
+	cache:=pokecache.NewCache(5*time.Minute)
+	cfg := &config{
+		Cache: cache,
+		HTTP: &http.Client{},
+		Input: readLines(os.Stdin),
+		Out: os.Stdout,
+	}
+	dir, err := dataDir()
+	if err != nil {
+		fmt.Fprintln(cfg.Out, "Error finding data directory:", err)
+		os.Exit(1)
+	}
+	cfg.SavePath = filepath.Join(dir, "save.json")
+	if err := loadGame(cfg); err != nil {
+		fmt.Fprintln(cfg.Out, "Error loading save:", err)
+		os.Exit(1)
+	}
+	if len(os.Args) > 1 {
+		if err := runSubcommand(cfg, os.Args[1:]); err != nil {
+			fmt.Fprintln(cfg.Out, "Error:", err)
+			os.Exit(1)
 		}
+		return
 	}
-}
\ No newline at end of file
+	repl(cfg, cliCommands())
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/glitchdawg/pokedex/internal/metrics"
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+// pokeAPILatency is shared by every session in the process, like the cache.
+var pokeAPILatency = metrics.NewHistogram(
+	"pokedex_pokeapi_request_duration_seconds",
+	"How long PokeAPI took to respond, by endpoint and HTTP status.",
+	metrics.DefaultBuckets, "endpoint", "status",
+)
+
+// knownEndpoints are the PokeAPI resources and outside hosts the app fetches
+// from. Only these get a series of their own: the proxy lets clients ask for
+// any path, and a series per path would grow the histogram without bound.
+var knownEndpoints = map[string]bool{
+	"ability":                   true,
+	"evolution-chain":           true,
+	"generation":                true,
+	"growth-rate":               true,
+	"item":                      true,
+	"location-area":             true,
+	"move":                      true,
+	"pokedex":                   true,
+	"pokemon":                   true,
+	"pokemon-species":           true,
+	"type":                      true,
+	"version":                   true,
+	"raw.githubusercontent.com": true,
+}
+
+// endpointLabel names the kind of resource at rawURL, e.g. "pokemon" for
+// https://pokeapi.co/api/v2/pokemon/pikachu, so the latency histogram has a
+// series per endpoint rather than per pokemon. Anything outside PokeAPI,
+// like sprites, goes by its host, and anything not in knownEndpoints is
+// "other".
+func endpointLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "other"
+	}
+	endpoint := u.Host
+	if path, ok := strings.CutPrefix(u.Path, "/api/v2/"); ok {
+		endpoint, _, _ = strings.Cut(path, "/")
+	}
+	if !knownEndpoints[endpoint] {
+		return "other"
+	}
+	return endpoint
+}
+
+// metricsHandler serves the cache's counters and the PokeAPI latency
+// histogram for Prometheus to scrape.
+func metricsHandler(cache *pokecache.Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		stats := cache.Stats()
+		metrics.WriteCounter(w, "pokedex_cache_hits_total", "Cache lookups that found an entry.", float64(stats.Hits))
+		metrics.WriteCounter(w, "pokedex_cache_misses_total", "Cache lookups that found nothing.", float64(stats.Misses))
+		metrics.WriteCounter(w, "pokedex_cache_evictions_total", "Cache entries dropped for being too old.", float64(stats.Evictions))
+		metrics.WriteGauge(w, "pokedex_cache_entries", "Entries in the cache.", float64(stats.Entries))
+		metrics.WriteGauge(w, "pokedex_cache_bytes", "Bytes stored in the cache.", float64(stats.Bytes))
+		pokeAPILatency.WriteTo(w)
+	})
+}
+
+// startMetrics serves /metrics on addr in the background. The returned stop
+// function shuts it down.
+func startMetrics(addr string, cache *pokecache.Cache) (stop func(), err error) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler(cache))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %v", err)
+	}
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	fmt.Printf("Serving metrics on %s/metrics\n", ln.Addr())
+	return func() { srv.Shutdown(context.Background()) }, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://pokeapi.co/api/v2/pokemon/pikachu":                       "pokemon",
+		"https://pokeapi.co/api/v2/location-area/?offset=20&limit=20":     "location-area",
+		"https://raw.githubusercontent.com/PokeAPI/sprites/master/25.png": "raw.githubusercontent.com",
+		"https://pokeapi.co/api/v2/pokemon-species/25/":                   "pokemon-species",
+		"https://pokeapi.co/api/v2/made-up-1/":                            "other",
+		"https://pokeapi.co/api/v2/../../etc/passwd":                      "other",
+		"https://example.com/pikachu.png":                                 "other",
+	} {
+		if got := endpointLabel(url); got != want {
+			t.Errorf("endpointLabel(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	pokeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missingno") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer pokeAPI.Close()
+	c := &config{Cache: pokecache.NewCache(time.Hour), HTTP: pokeAPI.Client()}
+	if _, err := fetchBytes(c, pokeAPI.URL+"/api/v2/pokemon/pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	fetchBytes(c, pokeAPI.URL+"/api/v2/pokemon/pikachu")
+	fetchBytes(c, pokeAPI.URL+"/api/v2/pokemon/missingno")
+
+	rec := httptest.NewRecorder()
+	metricsHandler(c.Cache).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		"pokedex_cache_hits_total 1\n",
+		"pokedex_cache_misses_total 2\n",
+		"pokedex_cache_entries 1\n",
+		"pokedex_cache_bytes 18\n",
+		`pokedex_pokeapi_request_duration_seconds_count{endpoint="pokemon",status="200"}`,
+		`pokedex_pokeapi_request_duration_seconds_count{endpoint="pokemon",status="404"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected metrics to contain %q, got\n%s", want, body)
+		}
+	}
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+}
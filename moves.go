@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// learnMethodOrder is the order learnset groups are printed in; any other
+// method PokeAPI knows about is listed after these.
+var learnMethodOrder = []string{"level-up", "machine", "tutor", "egg"}
+
+func fetchMove(c *config, name string) (Move, error) {
+	move := Move{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/move/%s", name)
+	if err := fetchJSON(c, url, &move); err != nil {
+		return move, fmt.Errorf("failed to fetch move %s: %v", name, err)
+	}
+	return move, nil
+}
+
+// checkLearnable makes sure pokemon can learn every one of moves, in any
+// version group and by any method.
+func checkLearnable(pokemon Pokemon, moves []string) error {
+	learnable := []string{}
+	for _, move := range pokemon.Moves {
+		learnable = append(learnable, move.Move.Name)
+	}
+	for _, move := range moves {
+		if !contains(learnable, move) {
+			return fmt.Errorf("%s can't learn %s", pokemon.Name, move)
+		}
+	}
+	return nil
+}
+
+// learnset groups the moves a pokemon can learn in a version group by learn
+// method. Level-up moves are ordered by level, everything else by name.
+func learnset(pokemon Pokemon, versionGroup string) map[string][]learnableMove {
+	groups := make(map[string][]learnableMove)
+	for _, move := range pokemon.Moves {
+		for _, detail := range move.VersionGroupDetails {
+			if detail.VersionGroup.Name != versionGroup {
+				continue
+			}
+			method := detail.MoveLearnMethod.Name
+			groups[method] = append(groups[method], learnableMove{name: move.Move.Name, level: detail.LevelLearnedAt})
+		}
+	}
+	for _, moves := range groups {
+		sort.SliceStable(moves, func(i, j int) bool {
+			if moves[i].level != moves[j].level {
+				return moves[i].level < moves[j].level
+			}
+			return moves[i].name < moves[j].name
+		})
+	}
+	return groups
+}
+
+func printLearnset(w io.Writer, pokemon Pokemon, versionGroup string) {
+	groups := learnset(pokemon, versionGroup)
+	if len(groups) == 0 {
+		fmt.Fprintf(w, "%s cannot learn any moves in %s\n", pokemon.Name, versionGroup)
+		return
+	}
+	methods := append([]string{}, learnMethodOrder...)
+	others := []string{}
+	for method := range groups {
+		known := false
+		for _, m := range learnMethodOrder {
+			if m == method {
+				known = true
+			}
+		}
+		if !known {
+			others = append(others, method)
+		}
+	}
+	sort.Strings(others)
+	methods = append(methods, others...)
+
+	fmt.Fprintf(w, "Learnset (%s):\n", versionGroup)
+	for _, method := range methods {
+		moves, ok := groups[method]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "  %s:\n", method)
+		for _, move := range moves {
+			if method == "level-up" {
+				fmt.Fprintf(w, "    Lv. %-3d %s\n", move.level, move.name)
+			} else {
+				fmt.Fprintf(w, "    %s\n", move.name)
+			}
+		}
+	}
+}
+
+// moveEffect returns the English effect text of a move with its effect
+// chance filled in.
+func moveEffect(move Move) string {
+	for _, entry := range move.EffectEntries {
+		if entry.Language.Name == "en" {
+			return strings.ReplaceAll(entry.ShortEffect, "$effect_chance", strconv.Itoa(move.EffectChance))
+		}
+	}
+	return ""
+}
+
+func commandMove(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("please provide a move name")
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("too many arguments, please provide only one move name")
+	}
+	move, err := fetchMove(c, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Name: %s\n", move.Name)
+	fmt.Fprintf(c.Out, "Type: %s\n", move.Type.Name)
+	fmt.Fprintf(c.Out, "Damage class: %s\n", move.DamageClass.Name)
+	if move.Power > 0 {
+		fmt.Fprintf(c.Out, "Power: %d\n", move.Power)
+	} else {
+		fmt.Fprintln(c.Out, "Power: -")
+	}
+	if move.Accuracy > 0 {
+		fmt.Fprintf(c.Out, "Accuracy: %d\n", move.Accuracy)
+	} else {
+		fmt.Fprintln(c.Out, "Accuracy: -")
+	}
+	fmt.Fprintf(c.Out, "PP: %d\n", move.PP)
+	fmt.Fprintf(c.Out, "Priority: %d\n", move.Priority)
+	if effect := moveEffect(move); effect != "" {
+		fmt.Fprintf(c.Out, "Effect: %s\n", effect)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+)
+
+const maxPartySize = 6
+
+// addToParty puts a caught pokemon in the party if there is room for it.
+func addToParty(c *config, name string) bool {
+	if len(c.Party) >= maxPartySize {
+		return false
+	}
+	for _, member := range c.Party {
+		if member == name {
+			return false
+		}
+	}
+	c.Party = append(c.Party, name)
+	return true
+}
+
+func removeFromParty(c *config, name string) bool {
+	for i, member := range c.Party {
+		if member == name {
+			c.Party = append(c.Party[:i], c.Party[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// partyLead returns the first pokemon in the party, or nil when it is empty.
+func partyLead(c *config) *CaughtPokemon {
+	if len(c.Party) == 0 {
+		return nil
+	}
+	return c.Pokedex[c.Party[0]]
+}
+
+func commandParty(c *config, args []string) error {
+	if len(args) == 1 {
+		if len(c.Party) == 0 {
+			fmt.Fprintln(c.Out, "Your party is empty.")
+			return nil
+		}
+		fmt.Fprintln(c.Out, "Your party:")
+		for i, name := range c.Party {
+			fmt.Fprintf(c.Out, "%d. %s (Lv. %d)\n", i+1, name, c.Pokedex[name].Level)
+		}
+		return nil
+	}
+	if len(args) != 3 {
+		return fmt.Errorf("usage: party [add|remove|lead <name>]")
+	}
+	name := args[2]
+	if _, ok := c.Pokedex[name]; !ok {
+		return fmt.Errorf("pokemon not found in your Pokedex")
+	}
+	switch args[1] {
+	case "add":
+		if !addToParty(c, name) {
+			return fmt.Errorf("%s is already in your party or your party is full", name)
+		}
+		fmt.Fprintf(c.Out, "%s joined your party.\n", name)
+	case "remove":
+		if !removeFromParty(c, name) {
+			return fmt.Errorf("%s is not in your party", name)
+		}
+		fmt.Fprintf(c.Out, "%s left your party.\n", name)
+	case "lead":
+		if !removeFromParty(c, name) && len(c.Party) >= maxPartySize {
+			return fmt.Errorf("your party is full")
+		}
+		c.Party = append([]string{name}, c.Party...)
+		fmt.Fprintf(c.Out, "%s now leads your party.\n", name)
+	default:
+		return fmt.Errorf("unknown party action: %s", args[1])
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pokedexQuery is how the pokedex command filters and orders the collection.
+type pokedexQuery struct {
+	types    []string
+	minStats map[string]int
+	sortBy   string
+	reverse  bool
+	limit    int
+}
+
+func parsePokedexQuery(args []string) (pokedexQuery, error) {
+	query := pokedexQuery{minStats: make(map[string]int), sortBy: "id"}
+	value := func(i int) (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("%s needs a value", args[i])
+		}
+		return args[i+1], nil
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--type":
+			v, err := value(i)
+			if err != nil {
+				return query, err
+			}
+			query.types = append(query.types, v)
+			i++
+		case "--min-stat":
+			v, err := value(i)
+			if err != nil {
+				return query, err
+			}
+			stat, min, ok := strings.Cut(v, "=")
+			n, err := strconv.Atoi(min)
+			if !ok || err != nil {
+				return query, fmt.Errorf("--min-stat must look like attack=80")
+			}
+			if !contains(statNames, stat) {
+				return query, fmt.Errorf("unknown stat %s, use one of %s", stat, strings.Join(statNames, ", "))
+			}
+			query.minStats[stat] = n
+			i++
+		case "--sort":
+			v, err := value(i)
+			if err != nil {
+				return query, err
+			}
+			switch v {
+			case "id", "name", "weight", "caught-at":
+				query.sortBy = v
+			default:
+				return query, fmt.Errorf("can only sort by id, name, weight or caught-at")
+			}
+			i++
+		case "--reverse":
+			query.reverse = true
+		case "--limit":
+			v, err := value(i)
+			if err != nil {
+				return query, err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return query, fmt.Errorf("--limit must be a positive number")
+			}
+			query.limit = n
+			i++
+		default:
+			return query, fmt.Errorf("unknown option: %s", args[i])
+		}
+	}
+	return query, nil
+}
+
+func hasType(pokemon Pokemon, name string) bool {
+	for _, t := range pokemon.Types {
+		if t.Type.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func baseStat(pokemon Pokemon, name string) int {
+	for _, stat := range pokemon.Stats {
+		if stat.Stat.Name == name {
+			return stat.BaseStat
+		}
+	}
+	return 0
+}
+
+func (q pokedexQuery) matches(pokemon *CaughtPokemon) bool {
+	for _, t := range q.types {
+		if !hasType(pokemon.Pokemon, t) {
+			return false
+		}
+	}
+	for stat, min := range q.minStats {
+		if baseStat(pokemon.Pokemon, stat) < min {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the caught pokemon matching the query in the order it asks for.
+func (q pokedexQuery) apply(pokedex map[string]*CaughtPokemon) []*CaughtPokemon {
+	results := []*CaughtPokemon{}
+	for _, pokemon := range pokedex {
+		if q.matches(pokemon) {
+			results = append(results, pokemon)
+		}
+	}
+	less := func(a, b *CaughtPokemon) bool {
+		switch q.sortBy {
+		case "name":
+			return a.Name < b.Name
+		case "weight":
+			if a.Weight != b.Weight {
+				return a.Weight < b.Weight
+			}
+		case "caught-at":
+			if !a.CaughtAt.Equal(b.CaughtAt) {
+				return a.CaughtAt.Before(b.CaughtAt)
+			}
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Name < b.Name
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if q.reverse {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
+	if q.limit > 0 && len(results) > q.limit {
+		results = results[:q.limit]
+	}
+	return results
+}
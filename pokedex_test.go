@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+// testCaught builds a caught pokemon with the given types and base stats.
+func testCaught(t *testing.T, id int, name string, weight int, types []string, stats map[string]int) *CaughtPokemon {
+	t.Helper()
+	typeList := ""
+	for i, typ := range types {
+		if i > 0 {
+			typeList += ","
+		}
+		typeList += fmt.Sprintf(`{"slot":%d,"type":{"name":%q}}`, i+1, typ)
+	}
+	statList := ""
+	for stat, base := range stats {
+		if statList != "" {
+			statList += ","
+		}
+		statList += fmt.Sprintf(`{"base_stat":%d,"stat":{"name":%q}}`, base, stat)
+	}
+	pokemon := Pokemon{}
+	data := fmt.Sprintf(`{"id":%d,"name":%q,"weight":%d,"species":{"name":%q},"types":[%s],"stats":[%s]}`, id, name, weight, name, typeList, statList)
+	if err := json.Unmarshal([]byte(data), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	return &CaughtPokemon{Pokemon: pokemon, Level: 5}
+}
+
+func testPokedex(t *testing.T) map[string]*CaughtPokemon {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pokedex := map[string]*CaughtPokemon{
+		"charizard":  testCaught(t, 6, "charizard", 905, []string{"fire", "flying"}, map[string]int{"attack": 84, "speed": 100}),
+		"charmander": testCaught(t, 4, "charmander", 85, []string{"fire"}, map[string]int{"attack": 52, "speed": 65}),
+		"squirtle":   testCaught(t, 7, "squirtle", 90, []string{"water"}, map[string]int{"attack": 48, "speed": 43}),
+		"arcanine":   testCaught(t, 59, "arcanine", 1550, []string{"fire"}, map[string]int{"attack": 110, "speed": 95}),
+	}
+	pokedex["arcanine"].CaughtAt = start
+	pokedex["squirtle"].CaughtAt = start.Add(time.Hour)
+	pokedex["charizard"].CaughtAt = start.Add(2 * time.Hour)
+	pokedex["charmander"].CaughtAt = start.Add(3 * time.Hour)
+	return pokedex
+}
+
+func names(pokemon []*CaughtPokemon) []string {
+	result := []string{}
+	for _, p := range pokemon {
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+func TestPokedexQuery(t *testing.T) {
+	pokedex := testPokedex(t)
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{}, expected: []string{"charmander", "charizard", "squirtle", "arcanine"}},
+		{args: []string{"--type", "fire", "--min-stat", "attack=80"}, expected: []string{"charizard", "arcanine"}},
+		{args: []string{"--sort", "weight", "--reverse", "--limit", "2"}, expected: []string{"arcanine", "charizard"}},
+		{args: []string{"--sort", "caught-at"}, expected: []string{"arcanine", "squirtle", "charizard", "charmander"}},
+		{args: []string{"--type", "fire", "--type", "flying"}, expected: []string{"charizard"}},
+	}
+	for _, c := range cases {
+		query, err := parsePokedexQuery(c.args)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", c.args, err)
+		}
+		actual := names(query.apply(pokedex))
+		if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("%v: expected %v, got %v", c.args, c.expected, actual)
+		}
+	}
+	for _, args := range [][]string{{"--sort", "height"}, {"--min-stat", "attack"}, {"--min-stat", "atk=80"}, {"--limit"}, {"--shiny"}} {
+		if _, err := parsePokedexQuery(args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestCatchExperienceNeedsAKeptCatch(t *testing.T) {
+	c := &config{Cache: pokecache.NewCache(time.Hour), Out: io.Discard, Pokedex: testPokedex(t), Bag: map[string]int{"master-ball": 2}, Seen: make(map[string]bool), ShinyHunt: make(map[string]int)}
+	addTestKadabra(t, c.Cache)
+	kadabra, err := fetchPokemon(c, "kadabra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kadabra.BaseExperience = 140
+	lead := c.Pokedex["charizard"]
+	lead.Level, lead.Experience, lead.GrowthRate = 5, 125, "medium"
+	c.Party = []string{"charizard"}
+	old := testCaught(t, 64, "kadabra", 565, []string{"psychic"}, nil)
+	old.GrowthRate = "medium"
+	c.Pokedex["kadabra"] = old
+
+	input := make(chan string, 1)
+	input <- "n"
+	c.Input = input
+	if _, err := catchPokemon(c, kadabra, "master-ball"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Pokedex["kadabra"] != old || lead.Experience != 125 {
+		t.Errorf("expected a catch let go to give no experience, got %d", lead.Experience)
+	}
+
+	// A lead replaced by the new catch hands its place over, so the
+	// experience goes to the pokemon that stays. Test growth rates need
+	// level cubed experience for each level.
+	c.Party = []string{"kadabra"}
+	input <- "y"
+	caught, err := catchPokemon(c, kadabra, "master-ball")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caught == nil || c.Pokedex["kadabra"] != caught || caught.Experience <= caught.Level*caught.Level*caught.Level {
+		t.Errorf("expected the kept kadabra to gain experience, got %+v", caught)
+	}
+}
+
+func TestConfirmReplace(t *testing.T) {
+	c := &config{Pokedex: testPokedex(t), Out: io.Discard}
+	if !confirmReplace(c, "pikachu") {
+		t.Errorf("expected a new species to need no confirmation")
+	}
+	if confirmReplace(c, "squirtle") {
+		t.Errorf("expected no replacement when the trainer can't be asked")
+	}
+	for answer, want := range map[string]bool{"y": true, "Yes": true, "n": false, "": false} {
+		input := make(chan string, 1)
+		input <- answer
+		c.Input = input
+		if got := confirmReplace(c, "squirtle"); got != want {
+			t.Errorf("answering %q: expected %v, got %v", answer, want, got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const progressBarWidth = 20
+
+type progressCount struct {
+	name   string
+	seen   int
+	caught int
+	total  int
+}
+
+func markSeen(c *config, name string) {
+	c.Seen[name] = true
+}
+
+func countProgress(name string, members []string, seen, caught map[string]bool) progressCount {
+	count := progressCount{name: name, total: len(members)}
+	for _, member := range members {
+		if caught[member] {
+			count.caught++
+		}
+		if seen[member] || caught[member] {
+			count.seen++
+		}
+	}
+	return count
+}
+
+// progressBar draws the caught share of a category as a bar with a percentage.
+func progressBar(caught, total int) string {
+	if total == 0 {
+		return ""
+	}
+	filled := caught * progressBarWidth / total
+	return fmt.Sprintf("[%s%s] %5.1f%%", strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), 100*float64(caught)/float64(total))
+}
+
+func printProgress(w io.Writer, p progressCount) {
+	fmt.Fprintf(w, "  %-20s seen %4d/%-4d caught %4d/%-4d %s\n", p.name, p.seen, p.total, p.caught, p.total, progressBar(p.caught, p.total))
+}
+
+func fetchResourceList(c *config, resource string) (NamedResourceList, error) {
+	list := NamedResourceList{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/%s?limit=1000", resource)
+	if err := fetchJSON(c, url, &list); err != nil {
+		return list, fmt.Errorf("failed to fetch %s list: %v", resource, err)
+	}
+	return list, nil
+}
+
+func fetchPokedex(c *config, name string) (Pokedex, error) {
+	dex := Pokedex{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokedex/%s", name)
+	if err := fetchJSON(c, url, &dex); err != nil {
+		return dex, fmt.Errorf("failed to fetch pokedex %s: %v", name, err)
+	}
+	return dex, nil
+}
+
+func pokedexSpecies(dex Pokedex) []string {
+	species := []string{}
+	for _, entry := range dex.PokemonEntries {
+		species = append(species, entry.PokemonSpecies.Name)
+	}
+	return species
+}
+
+func commandProgress(c *config, args []string) error {
+	section := ""
+	if len(args) == 2 {
+		section = args[1]
+	} else if len(args) > 2 {
+		return fmt.Errorf("usage: progress [national|generation|region|type]")
+	}
+	switch section {
+	case "", "national", "generation", "region", "type":
+	default:
+		return fmt.Errorf("unknown progress section: %s", section)
+	}
+
+	// Dex lists are of species, while types list individual pokemon.
+	caughtSpecies := make(map[string]bool)
+	caughtPokemon := make(map[string]bool)
+	for name, pokemon := range c.Pokedex {
+		caughtSpecies[pokemon.Species.Name] = true
+		caughtPokemon[name] = true
+	}
+
+	if section == "" || section == "national" {
+		dex, err := fetchPokedex(c, "national")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.Out, "National Pokedex:")
+		printProgress(c.Out, countProgress("national", pokedexSpecies(dex), c.Seen, caughtSpecies))
+	}
+
+	if section == "" || section == "generation" {
+		list, err := fetchResourceList(c, "generation")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.Out, "By generation:")
+		for _, result := range list.Results {
+			generation := Generation{}
+			if err := fetchJSON(c, result.URL, &generation); err != nil {
+				return fmt.Errorf("failed to fetch generation %s: %v", result.Name, err)
+			}
+			species := []string{}
+			for _, s := range generation.PokemonSpecies {
+				species = append(species, s.Name)
+			}
+			printProgress(c.Out, countProgress(generation.Name, species, c.Seen, caughtSpecies))
+		}
+	}
+
+	if section == "" || section == "region" {
+		list, err := fetchResourceList(c, "pokedex")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.Out, "By regional Pokedex:")
+		for _, result := range list.Results {
+			if result.Name == "national" {
+				continue
+			}
+			dex, err := fetchPokedex(c, result.Name)
+			if err != nil {
+				return err
+			}
+			if len(dex.PokemonEntries) == 0 {
+				continue
+			}
+			printProgress(c.Out, countProgress(dex.Name, pokedexSpecies(dex), c.Seen, caughtSpecies))
+		}
+	}
+
+	if section == "" || section == "type" {
+		list, err := fetchResourceList(c, "type")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.Out, "By type:")
+		for _, result := range list.Results {
+			pokemonType, err := fetchType(c, result.Name)
+			if err != nil {
+				return err
+			}
+			if len(pokemonType.Pokemon) == 0 {
+				continue
+			}
+			pokemon := []string{}
+			for _, p := range pokemonType.Pokemon {
+				pokemon = append(pokemon, p.Pokemon.Name)
+			}
+			printProgress(c.Out, countProgress(pokemonType.Name, pokemon, c.Seen, caughtPokemon))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountProgress(t *testing.T) {
+	members := []string{"bulbasaur", "charmander", "squirtle", "pikachu"}
+	seen := map[string]bool{"charmander": true, "squirtle": true}
+	caught := map[string]bool{"squirtle": true, "pikachu": true}
+	count := countProgress("test", members, seen, caught)
+	if count.total != 4 || count.seen != 3 || count.caught != 2 {
+		t.Errorf("expected 3 seen and 2 caught of 4, got %+v", count)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	bar := progressBar(1, 4)
+	if !strings.HasPrefix(bar, "["+strings.Repeat("█", 5)+strings.Repeat("░", 15)+"]") {
+		t.Errorf("unexpected bar: %s", bar)
+	}
+	if !strings.HasSuffix(bar, " 25.0%") {
+		t.Errorf("expected 25.0%%, got %s", bar)
+	}
+	if progressBar(0, 0) != "" {
+		t.Error("expected no bar for an empty category")
+	}
+}
@@ -1,5 +1,6 @@
 package main
 import (
+	"strings"
 	"testing"
 )
 func TestCleanInput(t *testing.T){
@@ -42,4 +43,34 @@ func TestCleanInput(t *testing.T){
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestHelpListsOnlyCommands(t *testing.T) {
+	commands := cliCommands()
+	// Commands are listed after the welcome, usage and a blank line.
+	for _, line := range strings.Split(helpText, "\n")[3:] {
+		name := strings.TrimSuffix(strings.Fields(line)[0], ":")
+		if _, ok := commands[name]; !ok {
+			t.Errorf("help lists %s, which isn't a command", name)
+		}
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	commands := cliCommands()
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "CATCH Pikachu Great-Ball", expected: "catch pikachu great-ball"},
+		{input: "Export ~/Teams/Main.TXT --format CSV", expected: "export ~/Teams/Main.TXT --format CSV"},
+		{input: "import-showdown Team.txt", expected: "import-showdown Team.txt"},
+		{input: "ability stench --lang zh-Hans", expected: "ability stench --lang zh-Hans"},
+	}
+	for _, c := range cases {
+		command := commands[CleanInput(c.input)[0]]
+		if actual := strings.Join(commandArgs(command, c.input), " "); actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, actual)
+		}
+	}
+}
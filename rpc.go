@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// JSON-RPC 2.0 error codes. rpcCommandFailed is ours, from the range the
+// spec leaves to implementations.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcCommandFailed  = -32000
+)
+
+// rpcParamNames lets the commands that take positional arguments also take
+// them by name, e.g. {"pokemon": "pikachu", "ball": "great-ball"} for catch.
+var rpcParamNames = map[string][]string{
+	"explore": {"area"},
+	"catch":   {"pokemon", "ball"},
+	"inspect": {"pokemon"},
+}
+
+// rpcCommandNames are the commands clients can call. Battles are left out:
+// they wait on the trainer for every turn, and stdin carries requests.
+var rpcCommandNames = []string{
+	"exit", "help", "map", "mapb", "explore", "catch", "inspect", "pokedex",
+	"party", "move", "ability", "bag", "mart", "sprite", "cry", "shinyhunt",
+	"find", "compare", "stats", "statcalc", "coverage", "suggest-team",
+	"export", "export-showdown", "import-showdown", "trade", "progress",
+	"settings", "version",
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	// ID is nil for notifications, which get no response.
+	ID json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// rpcSession answers JSON-RPC requests, one per line, by running the REPL's
+// commands. Whatever a command prints comes back in the result's output
+// rather than on the protocol stream.
+type rpcSession struct {
+	c        *config
+	commands map[string]cliCommand
+	enc      *json.Encoder
+	// lastCatch is what the last ball thrown caught, if anything.
+	lastCatch *CaughtPokemon
+	exiting   bool
+}
+
+func newRPCSession(c *config, out io.Writer) *rpcSession {
+	all := cliCommands()
+	commands := make(map[string]cliCommand)
+	for _, name := range rpcCommandNames {
+		commands[name] = all[name]
+	}
+	s := &rpcSession{c: c, commands: commands, enc: json.NewEncoder(out)}
+	// Commands can't ask follow-up questions: stdin carries requests.
+	c.Input = nil
+	c.Out = io.Discard
+	c.OnCatch = s.notifyCatch
+	return s
+}
+
+// serve answers requests from in until it is closed or the client calls
+// exit.
+func (s *rpcSession) serve(in <-chan string) error {
+	for line := range in {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := s.handleLine([]byte(line)); err != nil {
+			return err
+		}
+		if s.exiting {
+			break
+		}
+	}
+	return saveGame(s.c)
+}
+
+func (s *rpcSession) handleLine(line []byte) error {
+	if !json.Valid(line) {
+		return s.enc.Encode(rpcFailure(nil, rpcParseError, "parse error"))
+	}
+	if line[0] != '[' {
+		if response := s.handle(line); response != nil {
+			return s.enc.Encode(response)
+		}
+		return nil
+	}
+	batch := []json.RawMessage{}
+	if err := json.Unmarshal(line, &batch); err != nil || len(batch) == 0 {
+		return s.enc.Encode(rpcFailure(nil, rpcInvalidRequest, "invalid request"))
+	}
+	responses := []*rpcResponse{}
+	for _, raw := range batch {
+		if response := s.handle(raw); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return s.enc.Encode(responses)
+}
+
+func rpcFailure(id json.RawMessage, code int, message string) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: code, Message: message}, ID: id}
+}
+
+// handle answers one request, or returns nil for a notification.
+func (s *rpcSession) handle(raw json.RawMessage) *rpcResponse {
+	request := rpcRequest{}
+	if err := json.Unmarshal(raw, &request); err != nil || request.JSONRPC != "2.0" || request.Method == "" {
+		return rpcFailure(request.ID, rpcInvalidRequest, "invalid request")
+	}
+	result, rpcErr := s.call(request.Method, request.Params)
+	if request.ID == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		response := rpcFailure(request.ID, rpcErr.Code, rpcErr.Message)
+		response.Error.Data = rpcErr.Data
+		return response
+	}
+	return &rpcResponse{JSONRPC: "2.0", Result: result, ID: request.ID}
+}
+
+// rpcArgs turns params into the words the REPL would have read, so
+// ["pikachu", "great-ball"] and {"pokemon": "pikachu", "ball": "great-ball"}
+// both call catch like `catch pikachu great-ball`.
+func rpcArgs(command cliCommand, params json.RawMessage) ([]string, error) {
+	method := command.name
+	words := []string{method}
+	if len(params) == 0 || string(params) == "null" {
+		return words, nil
+	}
+	positional := []string{}
+	if err := json.Unmarshal(params, &positional); err == nil {
+		return commandArgs(command, strings.Join(append(words, positional...), " ")), nil
+	}
+	named := map[string]string{}
+	if err := json.Unmarshal(params, &named); err != nil {
+		return nil, fmt.Errorf("params must be an array or object of strings")
+	}
+	missing := ""
+	for _, name := range rpcParamNames[method] {
+		value, ok := named[name]
+		if !ok {
+			missing = name
+			continue
+		}
+		if missing != "" {
+			return nil, fmt.Errorf("%s needs %s when %s is given", method, missing, name)
+		}
+		words = append(words, value)
+		delete(named, name)
+	}
+	for name := range named {
+		return nil, fmt.Errorf("%s has no parameter named %s", method, name)
+	}
+	return commandArgs(command, strings.Join(words, " ")), nil
+}
+
+// call runs a command and describes what it did. Every result carries the
+// command's output; the commands integrations care most about add
+// structured fields.
+func (s *rpcSession) call(method string, params json.RawMessage) (map[string]interface{}, *rpcError) {
+	command, ok := s.commands[method]
+	if _, exists := cliCommands()[method]; !ok && exists {
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("%s needs a trainer at the keyboard and can't be called over RPC", method)}
+	}
+	if !ok {
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+	}
+	args, err := rpcArgs(command, params)
+	if err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	mapURL := ""
+	switch method {
+	case "map":
+		mapURL = s.c.Next
+		if mapURL == "" {
+			mapURL = locationsURL(0)
+		}
+	case "mapb":
+		mapURL, _ = s.c.Previous.(string)
+	}
+	output := &bytes.Buffer{}
+	s.c.Out = output
+	s.lastCatch = nil
+	err = command.callback(s.c, args)
+	if saveErr := saveGame(s.c); saveErr != nil {
+		fmt.Fprintln(output, "Error saving game:", saveErr)
+	}
+	s.c.Out = io.Discard
+	if errors.Is(err, errExit) {
+		s.exiting = true
+		err = nil
+	}
+	if err != nil {
+		return nil, &rpcError{Code: rpcCommandFailed, Message: err.Error(), Data: map[string]string{"output": output.String()}}
+	}
+	result := map[string]interface{}{"output": output.String()}
+	switch method {
+	case "map", "mapb":
+		if mapURL == "" {
+			break
+		}
+		// The command just fetched this page, so it comes from the cache.
+		if locations, err := fetchLocations(mapURL, s.c); err == nil {
+			areas := []string{}
+			for _, location := range locations.Results {
+				areas = append(areas, location.Name)
+			}
+			result["areas"] = areas
+		}
+	case "explore":
+		if s.c.Area != nil {
+			result["area"] = s.c.Area.Name
+			result["pokemon"] = encounterNames(*s.c.Area)
+		}
+	case "catch":
+		result["caught"] = s.lastCatch != nil
+		if s.lastCatch != nil {
+			result["pokemon"] = newPokemonView(s.c, s.lastCatch)
+		}
+	case "inspect":
+		if pokemon, ok := s.c.Pokedex[args[1]]; ok {
+			result["pokemon"] = newPokemonView(s.c, pokemon)
+		}
+	case "pokedex":
+		views := []pokemonView{}
+		if query, err := parsePokedexQuery(args[1:]); err == nil {
+			for _, pokemon := range query.apply(s.c.Pokedex) {
+				views = append(views, newPokemonView(s.c, pokemon))
+			}
+		}
+		result["pokemon"] = views
+	}
+	return result, nil
+}
+
+func (s *rpcSession) notify(method string, params interface{}) {
+	if err := s.enc.Encode(rpcNotification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		fmt.Fprintln(os.Stderr, "Error sending notification:", err)
+	}
+}
+
+// notifyCatch tells the client about every ball thrown, whichever command
+// threw it.
+func (s *rpcSession) notifyCatch(pokemon Pokemon, ball string, caught *CaughtPokemon) {
+	s.lastCatch = caught
+	if caught == nil {
+		s.notify("escaped", map[string]string{"pokemon": pokemon.Name, "ball": ball})
+		return
+	}
+	s.notify("caught", map[string]interface{}{"ball": ball, "pokemon": newPokemonView(s.c, caught)})
+}
+
+func subcommandRPC(c *config, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("rpc takes no arguments")
+	}
+	in := c.Input
+	return newRPCSession(c, os.Stdout).serve(in)
+}
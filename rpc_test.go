@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+// rpcExchange sends lines to a fresh session and returns every message it
+// wrote back.
+func rpcExchange(t *testing.T, c *config, lines ...string) []map[string]interface{} {
+	t.Helper()
+	out := &bytes.Buffer{}
+	in := make(chan string, len(lines))
+	for _, line := range lines {
+		in <- line
+	}
+	close(in)
+	if err := newRPCSession(c, out).serve(in); err != nil {
+		t.Fatal(err)
+	}
+	messages := []map[string]interface{}{}
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var message interface{}
+		if err := dec.Decode(&message); err != nil {
+			t.Fatal(err)
+		}
+		if batch, ok := message.([]interface{}); ok {
+			for _, m := range batch {
+				messages = append(messages, m.(map[string]interface{}))
+			}
+			continue
+		}
+		messages = append(messages, message.(map[string]interface{}))
+	}
+	return messages
+}
+
+func testRPCConfig(t *testing.T) *config {
+	c := &config{Cache: pokecache.NewCache(time.Hour), Pokedex: testPokedex(t), SavePath: filepath.Join(t.TempDir(), "save.json")}
+	assignCaughtIDs(c)
+	return c
+}
+
+func rpcErrorCode(message map[string]interface{}) float64 {
+	if rpcErr, ok := message["error"].(map[string]interface{}); ok {
+		return rpcErr["code"].(float64)
+	}
+	return 0
+}
+
+func TestRPCCommands(t *testing.T) {
+	c := testRPCConfig(t)
+	c.Cache.Add(locationsURL(0), []byte(`{"count":1,"next":"","results":[{"name":"canalave-city-area"}]}`))
+	messages := rpcExchange(t, c,
+		`{"jsonrpc":"2.0","id":1,"method":"inspect","params":{"pokemon":"Squirtle"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"pokedex","params":["--type","fire"]}`,
+		`{"jsonrpc":"2.0","id":"three","method":"map"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"exit"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"help"}`,
+	)
+	if len(messages) != 4 {
+		t.Fatalf("expected a response per request up to exit, got %v", messages)
+	}
+	inspect := messages[0]["result"].(map[string]interface{})
+	if messages[0]["id"] != 1.0 || inspect["pokemon"].(map[string]interface{})["name"] != "squirtle" || !strings.Contains(inspect["output"].(string), "Name: squirtle") {
+		t.Errorf("unexpected inspect response: %v", messages[0])
+	}
+	if fire := messages[1]["result"].(map[string]interface{})["pokemon"].([]interface{}); len(fire) != 3 {
+		t.Errorf("expected three fire types, got %v", fire)
+	}
+	if areas := messages[2]["result"].(map[string]interface{})["areas"].([]interface{}); messages[2]["id"] != "three" || len(areas) != 1 || areas[0] != "canalave-city-area" {
+		t.Errorf("unexpected map response: %v", messages[2])
+	}
+}
+
+func TestRPCErrors(t *testing.T) {
+	messages := rpcExchange(t, testRPCConfig(t),
+		`{"jsonrpc":"2.0","id":1,"method":`,
+		`{"jsonrpc":"1.0","id":2,"method":"help"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"fly"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"catch","params":{"ball":"great-ball"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"inspect","params":["mew"]}`,
+		`{"jsonrpc":"2.0","method":"inspect","params":["mew"]}`,
+		`[]`,
+		`[{"jsonrpc":"2.0","id":6,"method":"fly"},{"jsonrpc":"2.0","method":"help"}]`,
+	)
+	want := []float64{rpcParseError, rpcInvalidRequest, rpcMethodNotFound, rpcInvalidParams, rpcCommandFailed, rpcInvalidRequest, rpcMethodNotFound}
+	if len(messages) != len(want) {
+		t.Fatalf("expected %d errors and nothing for notifications, got %v", len(want), messages)
+	}
+	for i, code := range want {
+		if got := rpcErrorCode(messages[i]); got != code {
+			t.Errorf("message %d: expected code %v, got %v", i, code, messages[i])
+		}
+	}
+	if messages[0]["id"] != nil || messages[4]["id"] != 5.0 || messages[6]["id"] != 6.0 {
+		t.Errorf("unexpected IDs: %v", messages)
+	}
+}
+
+func TestRPCNotifiesCatches(t *testing.T) {
+	c := testRPCConfig(t)
+	out := &bytes.Buffer{}
+	s := newRPCSession(c, out)
+	c.OnCatch(Pokemon{Name: "pikachu"}, "poke-ball", nil)
+	c.OnCatch(c.Pokedex["squirtle"].Pokemon, "great-ball", c.Pokedex["squirtle"])
+	if s.lastCatch != c.Pokedex["squirtle"] {
+		t.Errorf("expected the session to remember the catch")
+	}
+	dec := json.NewDecoder(out)
+	escaped, caught := rpcNotification{}, struct {
+		Method string
+		Params struct {
+			Ball    string
+			Pokemon pokemonView
+		}
+	}{}
+	if err := dec.Decode(&escaped); err != nil || escaped.Method != "escaped" || escaped.Params.(map[string]interface{})["pokemon"] != "pikachu" {
+		t.Errorf("unexpected escape notification %+v (%v)", escaped, err)
+	}
+	if err := dec.Decode(&caught); err != nil || caught.Method != "caught" || caught.Params.Ball != "great-ball" || caught.Params.Pokemon.Name != "squirtle" {
+		t.Errorf("unexpected catch notification %+v (%v)", caught, err)
+	}
+}
+
+func TestRPCRejectsInteractiveCommands(t *testing.T) {
+	messages := rpcExchange(t, testRPCConfig(t),
+		`{"jsonrpc":"2.0","id":1,"method":"host-battle","params":["--addr","127.0.0.1:0"]}`,
+		`{"jsonrpc":"2.0","id":2,"method":"join-battle","params":["127.0.0.1:1"]}`,
+		`{"jsonrpc":"2.0","id":3,"method":"battle","params":["mew"]}`,
+		`{"jsonrpc":"2.0","id":4,"method":"help"}`,
+	)
+	if len(messages) != 4 {
+		t.Fatalf("expected an answer to every request, got %v", messages)
+	}
+	for _, message := range messages[:3] {
+		if rpcErrorCode(message) != rpcMethodNotFound {
+			t.Errorf("expected %v to be rejected", message)
+		}
+	}
+	if rpcErrorCode(messages[3]) != 0 {
+		t.Errorf("expected help to still work, got %v", messages[3])
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// saveData is everything about a trainer that outlives a session.
+type saveData struct {
+	Pokedex      map[string]*CaughtPokemon `json:"pokedex"`
+	Party        []string                  `json:"party"`
+	Bag          map[string]int            `json:"bag"`
+	Money        int                       `json:"money"`
+	Version      string                    `json:"version"`
+	VersionGroup string                    `json:"version_group"`
+	Settings     *Settings                 `json:"settings"`
+	ShinyHunt    map[string]int            `json:"shiny_hunt"`
+	Seen         map[string]bool           `json:"seen"`
+	NextCaughtID int                       `json:"next_caught_id"`
+	// TrainerKey signs trade offers. It is stored as its seed.
+	TrainerKey     []byte                    `json:"trainer_key"`
+	PendingTrades  map[string]*CaughtPokemon `json:"pending_trades"`
+	AcceptedTrades map[string]bool           `json:"accepted_trades"`
+}
+
+// dataDir is where the save file and downloaded assets live. It can be moved
+// with POKEDEX_DATA_DIR.
+func dataDir() (string, error) {
+	if dir := os.Getenv("POKEDEX_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %v", err)
+	}
+	return filepath.Join(home, ".pokedex"), nil
+}
+
+// newGame resets a config to the state of a trainer setting out for the first time.
+func newGame(c *config) {
+	c.Pokedex = make(map[string]*CaughtPokemon)
+	c.Party = nil
+	c.Bag = map[string]int{"poke-ball": startingPokeBalls}
+	c.Money = startingMoney
+	c.Version = defaultVersion
+	c.VersionGroup = defaultVersionGroup
+	c.Settings = defaultSettings()
+	c.ShinyHunt = make(map[string]int)
+	c.Seen = make(map[string]bool)
+	c.NextCaughtID = 0
+	c.TrainerKey = newTrainerKey()
+	c.PendingTrades = make(map[string]*CaughtPokemon)
+	c.AcceptedTrades = make(map[string]bool)
+}
+
+// loadGame reads the save file at c.SavePath, starting a new game when there
+// is none yet.
+func loadGame(c *config) error {
+	newGame(c)
+	data, err := os.ReadFile(c.SavePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %v", err)
+	}
+	// Settings missing from older saves keep their defaults.
+	save := saveData{Settings: &c.Settings}
+	if err := json.Unmarshal(data, &save); err != nil {
+		return fmt.Errorf("failed to decode save file: %v", err)
+	}
+	if save.Pokedex != nil {
+		c.Pokedex = save.Pokedex
+	}
+	if save.Bag != nil {
+		c.Bag = save.Bag
+	}
+	c.Party = save.Party
+	c.Money = save.Money
+	if save.Version != "" {
+		c.Version = save.Version
+		c.VersionGroup = save.VersionGroup
+	}
+	if save.ShinyHunt != nil {
+		c.ShinyHunt = save.ShinyHunt
+	}
+	if save.Seen != nil {
+		c.Seen = save.Seen
+	}
+	c.NextCaughtID = save.NextCaughtID
+	if len(save.TrainerKey) == ed25519.SeedSize {
+		c.TrainerKey = ed25519.NewKeyFromSeed(save.TrainerKey)
+	}
+	if save.PendingTrades != nil {
+		c.PendingTrades = save.PendingTrades
+	}
+	if save.AcceptedTrades != nil {
+		c.AcceptedTrades = save.AcceptedTrades
+	}
+	assignCaughtIDs(c)
+	return nil
+}
+
+// saveGame writes the trainer's state to c.SavePath, replacing the previous
+// save only once the new one is fully written.
+func saveGame(c *config) error {
+	if c.SavePath == "" {
+		return nil
+	}
+	var trainerKey []byte
+	if c.TrainerKey != nil {
+		trainerKey = c.TrainerKey.Seed()
+	}
+	data, err := json.Marshal(saveData{
+		Pokedex:        c.Pokedex,
+		Party:          c.Party,
+		Bag:            c.Bag,
+		Money:          c.Money,
+		Version:        c.Version,
+		VersionGroup:   c.VersionGroup,
+		Settings:       &c.Settings,
+		ShinyHunt:      c.ShinyHunt,
+		Seen:           c.Seen,
+		NextCaughtID:   c.NextCaughtID,
+		TrainerKey:     trainerKey,
+		PendingTrades:  c.PendingTrades,
+		AcceptedTrades: c.AcceptedTrades,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode save file: %v", err)
+	}
+	// The save holds the trainer's signing key, so only the trainer gets to
+	// read it.
+	if err := os.MkdirAll(filepath.Dir(c.SavePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+	tmp := c.SavePath + ".tmp"
+	// WriteFile keeps the mode of a file left over from a failed save.
+	os.Remove(tmp)
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write save file: %v", err)
+	}
+	if err := os.Rename(tmp, c.SavePath); err != nil {
+		return fmt.Errorf("failed to write save file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	c := &config{SavePath: path}
+	if err := loadGame(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Bag["poke-ball"] != startingPokeBalls || c.Money != startingMoney {
+		t.Errorf("expected a new game, got bag %v and money %d", c.Bag, c.Money)
+	}
+
+	if !useItem(c, "poke-ball") {
+		t.Fatal("expected to use a poke-ball")
+	}
+	addItem(c, "potion", 2)
+	earnMoney(c, 150)
+	c.Pokedex["pikachu"] = &CaughtPokemon{Pokemon: Pokemon{Name: "pikachu"}, Level: 7}
+	c.Party = []string{"pikachu"}
+	if err := saveGame(c); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &config{SavePath: path}
+	if err := loadGame(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Bag["poke-ball"] != startingPokeBalls-1 || loaded.Bag["potion"] != 2 {
+		t.Errorf("unexpected bag after loading: %v", loaded.Bag)
+	}
+	if loaded.Money != startingMoney+150 {
+		t.Errorf("expected ₽%d, got ₽%d", startingMoney+150, loaded.Money)
+	}
+	if p, ok := loaded.Pokedex["pikachu"]; !ok || p.Level != 7 {
+		t.Errorf("expected pikachu at level 7 in the loaded Pokedex")
+	}
+	if len(loaded.Party) != 1 || loaded.Party[0] != "pikachu" {
+		t.Errorf("unexpected party after loading: %v", loaded.Party)
+	}
+}
+
+func TestSaveIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't apply on Windows")
+	}
+	dir := filepath.Join(t.TempDir(), "data")
+	path := filepath.Join(dir, "save.json")
+	// A temporary file left over from a failed save must not keep its mode.
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(path+".tmp", nil, 0o644)
+	c := &config{SavePath: path}
+	if err := loadGame(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveGame(c); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("expected the save, which holds the trainer key, to be 0600, got %o", mode)
+	}
+}
+
+func TestUseItemRunsOut(t *testing.T) {
+	c := &config{Bag: map[string]int{"great-ball": 1}}
+	if !useItem(c, "great-ball") {
+		t.Fatal("expected to use the last great-ball")
+	}
+	if useItem(c, "great-ball") {
+		t.Error("expected no great-balls to be left")
+	}
+	if _, ok := c.Bag["great-ball"]; ok {
+		t.Error("expected empty items to be removed from the bag")
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/query"
+)
+
+// shutdownTimeout is how long in-flight requests get to finish once the
+// server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// server exposes a trainer's Pokedex over HTTP. Handlers that touch the
+// trainer's state take mu, since the REPL commands they share code with
+// assume one caller at a time.
+type server struct {
+	mu sync.Mutex
+	c  *config
+	// log is where the server reports its own troubles; what commands print
+	// goes back in their responses.
+	log io.Writer
+}
+
+func newServer(c *config) *server {
+	s := &server{c: c, log: c.Out}
+	// Commands can't ask follow-up questions: nobody is at the terminal to
+	// answer, and waiting would hold mu for good.
+	c.Input = nil
+	c.Out = io.Discard
+	return s
+}
+
+// capture collects what the session prints until the next capture, for the
+// response to the request holding mu.
+func (s *server) capture() *bytes.Buffer {
+	output := &bytes.Buffer{}
+	s.c.Out = output
+	return output
+}
+
+// pokemonView is the JSON shape of a caught pokemon.
+type pokemonView struct {
+	CaughtID   int            `json:"caught_id"`
+	Name       string         `json:"name"`
+	Number     int            `json:"number"`
+	Level      int            `json:"level"`
+	Experience int            `json:"experience"`
+	Shiny      bool           `json:"shiny"`
+	Types      []string       `json:"types"`
+	Ability    string         `json:"ability"`
+	Abilities  []string       `json:"abilities"`
+	HeldItem   string         `json:"held_item,omitempty"`
+	Nature     string         `json:"nature"`
+	BaseStats  map[string]int `json:"base_stats"`
+	Stats      map[string]int `json:"stats"`
+	IVs        map[string]int `json:"ivs"`
+	EVs        map[string]int `json:"evs"`
+	Moves      []string       `json:"moves"`
+	Legendary  bool           `json:"legendary"`
+	Mythical   bool           `json:"mythical"`
+	InParty    bool           `json:"in_party"`
+	CaughtAt   time.Time      `json:"caught_at"`
+	Sprite     string         `json:"sprite,omitempty"`
+}
+
+func newPokemonView(c *config, p *CaughtPokemon) pokemonView {
+	view := pokemonView{
+		CaughtID:   p.CaughtID,
+		Name:       p.Name,
+		Number:     p.ID,
+		Level:      p.Level,
+		Experience: p.Experience,
+		Shiny:      p.Shiny,
+		Types:      typeNames(p.Pokemon),
+		Ability:    p.Ability,
+		Abilities:  abilityNames(p.Pokemon),
+		HeldItem:   p.HeldItem,
+		Nature:     p.Nature,
+		BaseStats:  make(map[string]int),
+		Stats:      p.currentStats(),
+		IVs:        p.IVs,
+		EVs:        p.EVs,
+		Moves:      p.KnownMoves,
+		Legendary:  p.Legendary,
+		Mythical:   p.Mythical,
+		InParty:    contains(c.Party, p.Name),
+		CaughtAt:   p.CaughtAt,
+	}
+	for _, stat := range p.Stats {
+		view.BaseStats[stat.Stat.Name] = stat.BaseStat
+	}
+	if sprite, err := spriteURL(p.Pokemon, p.Shiny, false, 0); err == nil {
+		view.Sprite = sprite
+	}
+	return view
+}
+
+// encounterNames lists the pokemon that can be found in an area.
+func encounterNames(area ExploredLocation) []string {
+	found := []string{}
+	for _, encounter := range area.PokemonEncounters {
+		found = append(found, encounter.Pokemon.Name)
+	}
+	return found
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /api/trainer", s.handleTrainer)
+	mux.HandleFunc("GET /api/pokemon", s.handleListPokemon)
+	mux.HandleFunc("GET /api/pokemon/{ref}", s.handleGetPokemon)
+	mux.HandleFunc("POST /api/explore", s.handleExplore)
+	mux.HandleFunc("POST /api/catch", s.handleCatch)
+	mux.HandleFunc("GET /api/locations", s.handleLocations)
+	mux.HandleFunc("GET /api/pokeapi/{path...}", s.handleProxy)
+	mux.Handle("GET /", dashboardHandler())
+	return mux
+}
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+func (s *server) handleTrainer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"trainer": myTrainerID(s.c),
+		"money":   s.c.Money,
+		"bag":     s.c.Bag,
+		"party":   s.c.Party,
+		"caught":  len(s.c.Pokedex),
+		"seen":    len(s.c.Seen),
+		"version": s.c.Version,
+	})
+}
+
+// handleListPokemon lists caught pokemon, filtered and ordered by a find
+// query in q.
+func (s *server) handleListPokemon(w http.ResponseWriter, r *http.Request) {
+	q, err := query.Parse(r.URL.Query().Get("q"), findSchema)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	views := []pokemonView{}
+	for _, pokemon := range findPokemon(s.c.Pokedex, q) {
+		views = append(views, newPokemonView(s.c, pokemon))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *server) handleGetPokemon(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pokemon, err := findCaught(s.c, strings.ToLower(r.PathValue("ref")))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newPokemonView(s.c, pokemon))
+}
+
+// decodeRequest reads a JSON request body, and the status to answer with if
+// it can't. Bodies have to be sent as application/json: a web page can post
+// anything else to localhost without the browser asking the server first,
+// but not that.
+func decodeRequest(r *http.Request, v interface{}) (int, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return http.StatusUnsupportedMediaType, errors.New("requests must be sent as application/json")
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("failed to decode request: %v", err)
+	}
+	return 0, nil
+}
+
+func (s *server) handleExplore(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Area string `json:"area"`
+	}{}
+	if status, err := decodeRequest(r, &request); err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if request.Area == "" {
+		writeError(w, http.StatusBadRequest, errors.New("please provide a location area name"))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output := s.capture()
+	area := ExploredLocation{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%s", strings.ToLower(request.Area))
+	if err := fetchJSON(s.c, url, &area); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	s.c.Area = &area
+	traverseLocations(s.c, area, request.Area)
+	money := s.c.Money
+	rewardExploring(s.c)
+	s.save()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"area": area.Name, "pokemon": encounterNames(area), "money_found": s.c.Money - money, "output": output.String()})
+}
+
+func (s *server) handleCatch(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Pokemon string `json:"pokemon"`
+		Ball    string `json:"ball"`
+	}{Ball: "poke-ball"}
+	if status, err := decodeRequest(r, &request); err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if request.Pokemon == "" {
+		writeError(w, http.StatusBadRequest, errors.New("please provide a pokemon name"))
+		return
+	}
+	if _, ok := ballBonus[request.Ball]; !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%s is not a kind of ball", request.Ball))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.c.Bag[request.Ball] == 0 {
+		writeError(w, http.StatusConflict, fmt.Errorf("you have no %s left, buy more at the mart", request.Ball))
+		return
+	}
+	pokemon, err := fetchPokemon(s.c, strings.ToLower(request.Pokemon))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	output := s.capture()
+	caught, err := catchPokemon(s.c, pokemon, request.Ball)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.save()
+	response := map[string]interface{}{"caught": caught != nil, "balls_left": s.c.Bag[request.Ball], "output": output.String()}
+	if caught != nil {
+		response["pokemon"] = newPokemonView(s.c, caught)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// proxyCachePrefix keeps the proxy's copies of PokeAPI responses apart from
+// what fetchJSON caches under the bare URL, which is only the fields the
+// game decodes.
+const proxyCachePrefix = "proxy:"
+
+// handleProxy serves PokeAPI through the session's cache, so tools sharing
+// the server don't each hit PokeAPI for the same resources. PokeAPI's status
+// and content type are passed on; only failing to reach it is a 502.
+func (s *server) handleProxy(w http.ResponseWriter, r *http.Request) {
+	url := "https://pokeapi.co/api/v2/" + r.PathValue("path")
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+	// Cached responses are kept with their content type on the first line.
+	if cached, ok := s.c.Cache.Get(proxyCachePrefix + url); ok {
+		contentType, body, _ := bytes.Cut(cached, []byte("\n"))
+		w.Header().Set("Content-Type", string(contentType))
+		w.Header().Set("X-Cache", "HIT")
+		w.Write(body)
+		return
+	}
+	resp, err := pokeAPIGet(s.c, url)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to read response: %v", err))
+		return
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode == http.StatusOK {
+		s.c.Cache.Add(proxyCachePrefix+url, append([]byte(contentType+"\n"), body...))
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Cache", "MISS")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
+
+func (s *server) save() {
+	if err := saveGame(s.c); err != nil {
+		fmt.Fprintln(s.log, "Error saving game:", err)
+	}
+}
+
+// serve runs the HTTP server until it is interrupted, then lets in-flight
+// requests finish and saves the game. Metrics are served on metricsAddr
+// unless it is empty.
+func serve(c *config, addr, metricsAddr string) error {
+	if metricsAddr != "" {
+		stopMetrics, err := startMetrics(metricsAddr, c.Cache)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+	s := newServer(c)
+	srv := &http.Server{Addr: addr, Handler: s.routes()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(s.log, "Serving the Pokedex on %s (dashboard at /, API description at /openapi.json)\n", addr)
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve: %v", err)
+	case <-ctx.Done():
+	}
+	fmt.Fprintln(s.log, "Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %v", err)
+	}
+	return saveGame(c)
+}
+
+// runSubcommand runs `pokedex <name> [flags]` instead of the REPL.
+func runSubcommand(c *config, args []string) error {
+	subcommands := map[string]func(*config, []string) error{
+		"host":  subcommandHost,
+		"rpc":   subcommandRPC,
+		"serve": subcommandServe,
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		names := []string{}
+		for name := range subcommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown subcommand %s, choose one of %s", args[0], strings.Join(names, ", "))
+	}
+	return run(c, args[1:])
+}
+
+func subcommandServe(c *config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	metricsAddr := flags.String("metrics-addr", "", "address to serve Prometheus metrics on, if any")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return serve(c, *addr, *metricsAddr)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+func testServer(t *testing.T) (*httptest.Server, *config) {
+	t.Helper()
+	c := &config{Cache: pokecache.NewCache(time.Hour), Out: io.Discard, Pokedex: testPokedex(t), Bag: map[string]int{"poke-ball": 1}, TrainerKey: newTrainerKey()}
+	assignCaughtIDs(c)
+	srv := httptest.NewServer(newServer(c).routes())
+	t.Cleanup(srv.Close)
+	return srv, c
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func postJSON(t *testing.T, url, body string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	result := map[string]interface{}{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestServerListPokemon(t *testing.T) {
+	srv, _ := testServer(t)
+	views := []pokemonView{}
+	status := getJSON(t, srv.URL+"/api/pokemon?q="+url.QueryEscape("type=fire order by attack desc limit 2"), &views)
+	if status != http.StatusOK || len(views) != 2 || views[0].Name != "arcanine" || views[1].Name != "charizard" {
+		t.Errorf("unexpected listing (%d): %+v", status, views)
+	}
+	if views[0].BaseStats["attack"] != 110 || views[1].Types[1] != "flying" {
+		t.Errorf("unexpected details: %+v", views)
+	}
+
+	all := []pokemonView{}
+	if getJSON(t, srv.URL+"/api/pokemon", &all); len(all) != 4 {
+		t.Errorf("expected every pokemon without a query, got %d", len(all))
+	}
+
+	failure := map[string]string{}
+	if status := getJSON(t, srv.URL+"/api/pokemon?q=speed>fast", &failure); status != http.StatusBadRequest || !strings.Contains(failure["error"], "is a number") {
+		t.Errorf("expected a syntax error, got %d %v", status, failure)
+	}
+}
+
+func TestServerGetPokemon(t *testing.T) {
+	srv, c := testServer(t)
+	view := pokemonView{}
+	if status := getJSON(t, srv.URL+"/api/pokemon/Squirtle", &view); status != http.StatusOK || view.Name != "squirtle" {
+		t.Errorf("unexpected pokemon (%d): %+v", status, view)
+	}
+	byID := pokemonView{}
+	if getJSON(t, srv.URL+"/api/pokemon/"+strconv.Itoa(c.Pokedex["arcanine"].CaughtID), &byID); byID.Name != "arcanine" {
+		t.Errorf("expected arcanine by caught ID, got %+v", byID)
+	}
+	failure := map[string]string{}
+	if status := getJSON(t, srv.URL+"/api/pokemon/mew", &failure); status != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+}
+
+func TestServerCatchChecksTheBag(t *testing.T) {
+	srv, c := testServer(t)
+	if status, body := postJSON(t, srv.URL+"/api/catch", `{"pokemon":"pikachu","ball":"rock"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a rock, got %d %v", status, body)
+	}
+	c.Bag = map[string]int{}
+	if status, body := postJSON(t, srv.URL+"/api/catch", `{"pokemon":"pikachu"}`); status != http.StatusConflict || !strings.Contains(body["error"].(string), "no poke-ball") {
+		t.Errorf("expected 409 without balls, got %d %v", status, body)
+	}
+	if status, _ := postJSON(t, srv.URL+"/api/explore", `{}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 exploring nowhere, got %d", status)
+	}
+}
+
+func TestServerCatchDoesNotAsk(t *testing.T) {
+	c := &config{Cache: pokecache.NewCache(time.Hour), SavePath: filepath.Join(t.TempDir(), "save.json")}
+	if err := loadGame(c); err != nil {
+		t.Fatal(err)
+	}
+	c.Pokedex, c.Bag = testPokedex(t), map[string]int{"master-ball": 1}
+	// Serve mode starts from the REPL's config, whose input is a terminal
+	// nobody is watching.
+	serverOut := &bytes.Buffer{}
+	c.Input, c.Out = make(chan string), serverOut
+	assignCaughtIDs(c)
+	addTestKadabra(t, c.Cache)
+	lead := c.Pokedex["charizard"]
+	lead.Level, lead.Experience, lead.GrowthRate = 5, 6*6*6-1, "medium"
+	lead.KnownMoves = []string{"scratch", "growl", "ember", "smokescreen"}
+	learnset := fmt.Sprintf(`{"moves":[{"move":{"name":"dragon-rage"},"version_group_details":[{"level_learned_at":6,"move_learn_method":{"name":"level-up"},"version_group":{"name":%q}}]}]}`, c.VersionGroup)
+	if err := json.Unmarshal([]byte(learnset), &lead.Pokemon); err != nil {
+		t.Fatal(err)
+	}
+	c.Party = []string{"charizard"}
+	// A catch stuck on a question would never let srv close, so it is
+	// closed only once the catch has answered.
+	srv := httptest.NewServer(newServer(c).routes())
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(srv.URL+"/api/catch", "application/json", strings.NewReader(`{"pokemon":"kadabra","ball":"master-ball"}`))
+	if err != nil {
+		t.Fatalf("expected the catch not to wait for an answer: %v", err)
+	}
+	defer srv.Close()
+	defer resp.Body.Close()
+	result := map[string]interface{}{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	output, _ := result["output"].(string)
+	if resp.StatusCode != http.StatusOK || result["caught"] != true || !strings.Contains(output, "charizard did not learn dragon-rage") {
+		t.Errorf("unexpected catch response %d %v", resp.StatusCode, result)
+	}
+	if serverOut.Len() != 0 {
+		t.Errorf("expected the catch's output in the response only, the server printed %q", serverOut)
+	}
+	if len(lead.KnownMoves) != 4 || lead.Level != 6 {
+		t.Errorf("expected charizard to grow without learning, got Lv. %d %v", lead.Level, lead.KnownMoves)
+	}
+}
+
+// redirectTransport sends every request to a test server instead of the
+// host it was meant for.
+type redirectTransport struct {
+	to *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = rt.to.Scheme, rt.to.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestServerProxyUsesCache(t *testing.T) {
+	srv, c := testServer(t)
+	pokeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":2,"next":null,"results":[]}`))
+	}))
+	defer pokeAPI.Close()
+	to, _ := url.Parse(pokeAPI.URL)
+	c.HTTP = &http.Client{Transport: redirectTransport{to: to}}
+	// fetchJSON keeps only the fields it decodes, which the proxy must not
+	// pass off as the whole response.
+	c.Cache.Add("https://pokeapi.co/api/v2/pokemon?limit=2", []byte(`{"count":2}`))
+
+	for _, want := range []string{"MISS", "HIT"} {
+		resp, err := http.Get(srv.URL + "/api/pokeapi/pokemon?limit=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != `{"count":2,"next":null,"results":[]}` || resp.Header.Get("X-Cache") != want {
+			t.Errorf("expected a %s with the full response, got %d %s %q", want, resp.StatusCode, resp.Header.Get("X-Cache"), body)
+		}
+	}
+}
+
+func TestServerRejectsCrossSitePosts(t *testing.T) {
+	srv, c := testServer(t)
+	// A form or fetch from another site can send text/plain without a
+	// preflight.
+	for _, path := range []string{"/api/explore", "/api/catch"} {
+		resp, err := http.Post(srv.URL+path, "text/plain", strings.NewReader(`{"area":"canalave-city-area","pokemon":"mew"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("%s: expected a text/plain post to be turned away, got %d", path, resp.StatusCode)
+		}
+	}
+	if c.Area != nil || c.Bag["poke-ball"] != 1 {
+		t.Errorf("expected nothing to change, got area %v and bag %v", c.Area, c.Bag)
+	}
+}
+
+func TestServerProxyPassesThroughPokeAPI(t *testing.T) {
+	srv, c := testServer(t)
+	pokeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missingno") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	}))
+	defer pokeAPI.Close()
+	to, _ := url.Parse(pokeAPI.URL)
+	c.HTTP = &http.Client{Transport: redirectTransport{to: to}}
+
+	for _, want := range []string{"MISS", "HIT"} {
+		resp, err := http.Get(srv.URL + "/api/pokeapi/sprite.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || resp.Header.Get("X-Cache") != want {
+			t.Errorf("expected a %s with PokeAPI's content type, got %d %s %s", want, resp.StatusCode, resp.Header.Get("Content-Type"), resp.Header.Get("X-Cache"))
+		}
+	}
+	resp, err := http.Get(srv.URL + "/api/pokeapi/pokemon/missingno")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected PokeAPI's 404 to be passed on, got %d", resp.StatusCode)
+	}
+
+	c.HTTP = &http.Client{Transport: redirectTransport{to: &url.URL{Scheme: "http", Host: "127.0.0.1:1"}}}
+	resp, err = http.Get(srv.URL + "/api/pokeapi/pokemon/ditto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected a 502 when PokeAPI can't be reached, got %d", resp.StatusCode)
+	}
+}
+
+func TestOpenAPIDescribesRoutes(t *testing.T) {
+	srv, _ := testServer(t)
+	spec := struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}{}
+	if status := getJSON(t, srv.URL+"/openapi.json", &spec); status != http.StatusOK || spec.OpenAPI == "" {
+		t.Fatalf("expected an OpenAPI document, got %d", status)
+	}
+	for path, method := range map[string]string{
+		"/api/trainer":        "get",
+		"/api/pokemon":        "get",
+		"/api/pokemon/{ref}":  "get",
+		"/api/explore":        "post",
+		"/api/catch":          "post",
+		"/api/locations":      "get",
+		"/api/pokeapi/{path}": "get",
+	} {
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("expected %s %s to be described", method, path)
+		}
+	}
+}
+
+func TestServerLocationsPages(t *testing.T) {
+	srv, c := testServer(t)
+	c.Cache.Add(locationsURL(0), []byte(`{"count":25,"next":"https://pokeapi.co/api/v2/location-area/?offset=20&limit=20","previous":null,"results":[{"name":"canalave-city-area"}]}`))
+	c.Cache.Add(locationsURL(20), []byte(`{"count":25,"next":null,"previous":"https://pokeapi.co/api/v2/location-area/","results":[{"name":"eterna-city-area"}]}`))
+
+	first := map[string]interface{}{}
+	if status := getJSON(t, srv.URL+"/api/locations", &first); status != http.StatusOK || first["next"] != 20.0 || first["previous"] != nil {
+		t.Errorf("unexpected first page (%d): %v", status, first)
+	}
+	last := map[string]interface{}{}
+	getJSON(t, srv.URL+"/api/locations?offset=20", &last)
+	if areas := last["areas"].([]interface{}); len(areas) != 1 || areas[0] != "eterna-city-area" || last["next"] != nil || last["previous"] != 0.0 {
+		t.Errorf("unexpected last page: %v", last)
+	}
+	failure := map[string]string{}
+	if status := getJSON(t, srv.URL+"/api/locations?offset=-1", &failure); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative offset, got %d", status)
+	}
+}
+
+func TestServerDashboard(t *testing.T) {
+	srv, _ := testServer(t)
+	for path, want := range map[string]string{"/": "<title>Pokedex</title>", "/app.js": "/api/catch"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("expected %s to serve %q, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glitchdawg/pokedex/internal/termimg"
+)
+
+// Settings are the trainer's preferences. They are kept in the save file.
+type Settings struct {
+	Graphics   string `json:"graphics"`
+	CryPlayer  string `json:"cry_player"`
+	CryOnCatch bool   `json:"cry_on_catch"`
+	ShinyOdds  int    `json:"shiny_odds"`
+}
+
+type setting struct {
+	name        string
+	description string
+	get         func(s *Settings) string
+	set         func(s *Settings, value string) error
+	// keepCase passes the value through as typed, for settings like
+	// commands where case matters.
+	keepCase bool
+}
+
+var settingList = []setting{
+	{
+		name:        "graphics",
+		description: "how images are drawn: auto, kitty, sixel, ansi or text",
+		get:         func(s *Settings) string { return s.Graphics },
+		set: func(s *Settings, value string) error {
+			switch value {
+			case "auto", termimg.ModeKitty, termimg.ModeSixel, termimg.ModeANSI, termimg.ModeText:
+				s.Graphics = value
+				return nil
+			}
+			return fmt.Errorf("graphics must be one of auto, kitty, sixel, ansi or text")
+		},
+	},
+	{
+		name:        "shiny-odds",
+		description: "chance of a wild pokemon being shiny, as 1/n, or off",
+		get: func(s *Settings) string {
+			if s.ShinyOdds <= 0 {
+				return "off"
+			}
+			return fmt.Sprintf("1/%d", s.ShinyOdds)
+		},
+		set: func(s *Settings, value string) (err error) {
+			s.ShinyOdds, err = parseShinyOdds(value)
+			return err
+		},
+	},
+	{
+		name:        "cry-player",
+		description: "command cries are played with, or none to print the file path",
+		get:         func(s *Settings) string { return orNone(s.CryPlayer) },
+		set: func(s *Settings, value string) error {
+			if strings.EqualFold(value, "none") {
+				value = ""
+			}
+			s.CryPlayer = value
+			return nil
+		},
+		keepCase: true,
+	},
+	{
+		name:        "cry-on-catch",
+		description: "play a pokemon's cry when it is caught: on or off",
+		get:         func(s *Settings) string { return onOff(s.CryOnCatch) },
+		set: func(s *Settings, value string) (err error) {
+			s.CryOnCatch, err = parseOnOff(value)
+			return err
+		},
+	},
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+func parseOnOff(value string) (bool, error) {
+	switch value {
+	case "on", "true", "yes":
+		return true, nil
+	case "off", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("please use on or off")
+}
+
+func defaultSettings() Settings {
+	return Settings{
+		Graphics:  "auto",
+		ShinyOdds: defaultShinyOdds,
+	}
+}
+
+// graphicsMode is the mode images are drawn in, detecting what the terminal
+// supports unless the trainer has picked one.
+func graphicsMode(c *config) string {
+	if c.Settings.Graphics == "" || c.Settings.Graphics == "auto" {
+		return termimg.Detect()
+	}
+	return c.Settings.Graphics
+}
+
+func commandSettings(c *config, args []string) error {
+	if len(args) == 1 {
+		for _, s := range settingList {
+			fmt.Fprintf(c.Out, "%s = %s (%s)\n", s.name, s.get(&c.Settings), s.description)
+		}
+		return nil
+	}
+	if len(args) < 3 {
+		return fmt.Errorf("usage: settings [<name> <value>]")
+	}
+	name, value := strings.ToLower(args[1]), strings.Join(args[2:], " ")
+	for _, s := range settingList {
+		if s.name == name {
+			if !s.keepCase {
+				value = strings.ToLower(value)
+			}
+			if err := s.set(&c.Settings, value); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.Out, "%s = %s\n", s.name, s.get(&c.Settings))
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting: %s", name)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSettingsKeepCommandCase(t *testing.T) {
+	c := &config{Out: io.Discard, Settings: defaultSettings()}
+	command := cliCommands()["settings"]
+	for _, line := range []string{
+		"Settings Cry-Player /Applications/VLC.app/Contents/MacOS/VLC --Intf Dummy",
+		"settings GRAPHICS Text",
+		"settings cry-on-catch ON",
+	} {
+		if err := command.callback(c, commandArgs(command, line)); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+	}
+	if want := "/Applications/VLC.app/Contents/MacOS/VLC --Intf Dummy"; c.Settings.CryPlayer != want {
+		t.Errorf("expected the cry player %q as typed, got %q", want, c.Settings.CryPlayer)
+	}
+	if c.Settings.Graphics != "text" || !c.Settings.CryOnCatch {
+		t.Errorf("expected other settings to ignore case, got %+v", c.Settings)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultShinyOdds is the chance, as 1 in n, that a wild pokemon is shiny.
+const defaultShinyOdds = 4096
+
+// encounterShiny rolls whether a wild pokemon of a species is shiny, keeping
+// count of how many of that species were met since the last shiny one. Being
+// encountered also marks the species as seen.
+func encounterShiny(c *config, species string) bool {
+	markSeen(c, species)
+	c.ShinyHunt[species]++
+	if c.Settings.ShinyOdds <= 0 || rand.Intn(c.Settings.ShinyOdds) != 0 {
+		return false
+	}
+	fmt.Fprintf(c.Out, "It's a shiny %s! That took %d encounters.\n", species, c.ShinyHunt[species])
+	c.ShinyHunt[species] = 0
+	return true
+}
+
+func parseShinyOdds(value string) (int, error) {
+	if value == "off" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(value, "1/"))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("shiny odds must look like 4096, 1/4096 or off")
+	}
+	return n, nil
+}
+
+func commandShinyHunt(c *config, args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("too many arguments, please provide only one pokemon name")
+	}
+	if len(args) == 2 {
+		fmt.Fprintf(c.Out, "%d encounters since the last shiny %s\n", c.ShinyHunt[args[1]], args[1])
+		return nil
+	}
+	if len(c.ShinyHunt) == 0 {
+		fmt.Fprintln(c.Out, "You haven't encountered any pokemon yet.")
+		return nil
+	}
+	species := []string{}
+	for name := range c.ShinyHunt {
+		species = append(species, name)
+	}
+	sort.Strings(species)
+	if c.Settings.ShinyOdds <= 0 {
+		fmt.Fprintln(c.Out, "Shinies are turned off, change shiny-odds in settings to hunt them.")
+		fmt.Fprintln(c.Out, "Encounters since the last shiny:")
+	} else {
+		fmt.Fprintf(c.Out, "Encounters since the last shiny (odds 1/%d):\n", c.Settings.ShinyOdds)
+	}
+	for _, name := range species {
+		fmt.Fprintf(c.Out, "- %s: %d\n", name, c.ShinyHunt[name])
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// showdownStats are the abbreviations Showdown uses in EV and IV lines.
+var showdownStats = map[string]string{
+	"hp":              "HP",
+	"attack":          "Atk",
+	"defense":         "Def",
+	"special-attack":  "SpA",
+	"special-defense": "SpD",
+	"speed":           "Spe",
+}
+
+// showdownSet is one pokemon of a Showdown paste. Names are kept as PokeAPI
+// slugs; Line is where the set starts in the paste, for error messages.
+type showdownSet struct {
+	Line     int
+	Nickname string
+	Species  string
+	Item     string
+	Ability  string
+	Level    int
+	Shiny    bool
+	Nature   string
+	EVs      map[string]int
+	IVs      map[string]int
+	Moves    []string
+}
+
+// showdownSlug turns a Showdown display name such as "Mr. Mime" or
+// "King's Rock" into a PokeAPI name.
+func showdownSlug(name string) string {
+	name = strings.NewReplacer("♀", "-f", "♂", "-m").Replace(strings.ToLower(name))
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteByte('-')
+		}
+	}
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '-' })
+	return strings.Join(parts, "-")
+}
+
+// showdownName turns a PokeAPI name into a display name. Showdown ignores
+// punctuation when importing, so "U Turn" is read back as U-turn.
+func showdownName(slug, sep string) string {
+	words := strings.Split(slug, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, sep)
+}
+
+func showdownSpread(values map[string]int, skip int) string {
+	parts := []string{}
+	for _, stat := range statNames {
+		if v, ok := values[stat]; ok && v != skip {
+			parts = append(parts, fmt.Sprintf("%d %s", v, showdownStats[stat]))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+func formatShowdown(sets []showdownSet) string {
+	var b strings.Builder
+	for i, set := range sets {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		species := showdownName(set.Species, "-")
+		if set.Nickname != "" {
+			species = fmt.Sprintf("%s (%s)", set.Nickname, species)
+		}
+		if set.Item != "" {
+			species += " @ " + showdownName(set.Item, " ")
+		}
+		fmt.Fprintln(&b, species)
+		if set.Ability != "" {
+			fmt.Fprintf(&b, "Ability: %s\n", showdownName(set.Ability, " "))
+		}
+		if set.Level != 0 && set.Level != maxLevel {
+			fmt.Fprintf(&b, "Level: %d\n", set.Level)
+		}
+		if set.Shiny {
+			b.WriteString("Shiny: Yes\n")
+		}
+		if evs := showdownSpread(set.EVs, 0); evs != "" {
+			fmt.Fprintf(&b, "EVs: %s\n", evs)
+		}
+		if set.Nature != "" {
+			fmt.Fprintf(&b, "%s Nature\n", showdownName(set.Nature, " "))
+		}
+		if ivs := showdownSpread(set.IVs, maxIV); ivs != "" {
+			fmt.Fprintf(&b, "IVs: %s\n", ivs)
+		}
+		for _, move := range set.Moves {
+			fmt.Fprintf(&b, "- %s\n", showdownName(move, " "))
+		}
+	}
+	return b.String()
+}
+
+func parseShowdownSpread(value string, max int) (map[string]int, error) {
+	spread := make(map[string]int)
+	for _, part := range strings.Split(value, "/") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expected something like \"252 Atk\" but found %q", strings.TrimSpace(part))
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 0 || n > max {
+			return nil, fmt.Errorf("%q must be a number from 0 to %d", fields[0], max)
+		}
+		stat := ""
+		for name, abbreviation := range showdownStats {
+			if strings.EqualFold(abbreviation, fields[1]) {
+				stat = name
+			}
+		}
+		if stat == "" {
+			return nil, fmt.Errorf("unknown stat %q", fields[1])
+		}
+		spread[stat] = n
+	}
+	return spread, nil
+}
+
+// parseShowdownHeader reads a set's first line: "Nickname (Species) (M) @ Item".
+func parseShowdownHeader(line string, set *showdownSet) {
+	if i := strings.LastIndex(line, " @ "); i >= 0 {
+		set.Item = showdownSlug(line[i+3:])
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	for _, gender := range []string{" (M)", " (F)"} {
+		line = strings.TrimSuffix(line, gender)
+	}
+	if open := strings.LastIndex(line, " ("); open >= 0 && strings.HasSuffix(line, ")") {
+		set.Nickname = line[:open]
+		line = line[open+2 : len(line)-1]
+	}
+	set.Species = showdownSlug(line)
+}
+
+// parseShowdown reads a Showdown paste. Lines for things this Pokedex doesn't
+// track, such as Tera Type or Happiness, are skipped.
+func parseShowdown(paste string) ([]showdownSet, error) {
+	sets := []showdownSet{}
+	var set *showdownSet
+	for n, line := range strings.Split(strings.ReplaceAll(paste, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		lineErr := func(format string, args ...interface{}) error {
+			return fmt.Errorf("line %d: %s", n+1, fmt.Sprintf(format, args...))
+		}
+		switch {
+		case line == "" || strings.HasPrefix(line, "==="):
+			set = nil
+			continue
+		case set == nil:
+			sets = append(sets, showdownSet{Line: n + 1, Level: maxLevel, EVs: make(map[string]int), IVs: make(map[string]int)})
+			set = &sets[len(sets)-1]
+			for _, stat := range statNames {
+				set.IVs[stat] = maxIV
+			}
+			parseShowdownHeader(line, set)
+			if set.Species == "" {
+				return nil, lineErr("expected a species")
+			}
+			continue
+		case strings.HasPrefix(line, "-"):
+			if len(set.Moves) == 4 {
+				return nil, lineErr("a pokemon can only know 4 moves")
+			}
+			set.Moves = append(set.Moves, showdownSlug(strings.TrimPrefix(line, "-")))
+			continue
+		case strings.HasSuffix(line, " Nature"):
+			set.Nature = showdownSlug(strings.TrimSuffix(line, " Nature"))
+			if _, ok := natures[set.Nature]; !ok {
+				return nil, lineErr("unknown nature %q", set.Nature)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, lineErr("unexpected %q", line)
+		}
+		value = strings.TrimSpace(value)
+		var err error
+		switch strings.ToLower(key) {
+		case "ability":
+			set.Ability = showdownSlug(value)
+		case "level":
+			set.Level, err = strconv.Atoi(value)
+			if err != nil || set.Level < 1 || set.Level > maxLevel {
+				return nil, lineErr("level must be a number from 1 to %d", maxLevel)
+			}
+		case "shiny":
+			set.Shiny = strings.EqualFold(value, "yes")
+		case "evs":
+			evs, err := parseShowdownSpread(value, maxEV)
+			if err != nil {
+				return nil, lineErr("%v", err)
+			}
+			total := 0
+			for stat, ev := range evs {
+				set.EVs[stat] = ev
+				total += ev
+			}
+			if total > maxTotalEVs {
+				return nil, lineErr("EVs add up to %d, more than %d", total, maxTotalEVs)
+			}
+		case "ivs":
+			ivs, err := parseShowdownSpread(value, maxIV)
+			if err != nil {
+				return nil, lineErr("%v", err)
+			}
+			for stat, iv := range ivs {
+				set.IVs[stat] = iv
+			}
+		}
+	}
+	return sets, nil
+}
+
+func showdownSetFor(p *CaughtPokemon) showdownSet {
+	return showdownSet{
+		Species: p.Name,
+		Item:    p.HeldItem,
+		Ability: p.Ability,
+		Level:   p.Level,
+		Shiny:   p.Shiny,
+		Nature:  p.Nature,
+		EVs:     p.EVs,
+		IVs:     p.IVs,
+		Moves:   p.KnownMoves,
+	}
+}
+
+func commandExportShowdown(c *config, args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: export-showdown [file]")
+	}
+	if len(c.Party) == 0 {
+		return fmt.Errorf("your party is empty")
+	}
+	sets := []showdownSet{}
+	for _, name := range c.Party {
+		sets = append(sets, showdownSetFor(c.Pokedex[name]))
+	}
+	paste := formatShowdown(sets)
+	if len(args) == 1 {
+		fmt.Fprint(c.Out, paste)
+		return nil
+	}
+	if err := os.WriteFile(args[1], []byte(paste), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", args[1], err)
+	}
+	fmt.Fprintf(c.Out, "Exported %d pokemon to %s\n", len(sets), args[1])
+	return nil
+}
+
+// caughtFromShowdown checks a set against PokeAPI and builds the caught
+// record for it.
+func caughtFromShowdown(c *config, set showdownSet) (*CaughtPokemon, error) {
+	pokemon, err := fetchPokemon(c, set.Species)
+	if err != nil {
+		return nil, fmt.Errorf("unknown species %s", set.Species)
+	}
+	if set.Ability != "" && !contains(abilityNames(pokemon), set.Ability) {
+		return nil, fmt.Errorf("%s can't have the ability %s", pokemon.Name, set.Ability)
+	}
+	learnable := []string{}
+	for _, move := range pokemon.Moves {
+		learnable = append(learnable, move.Move.Name)
+	}
+	for _, move := range set.Moves {
+		if _, err := fetchMove(c, move); err != nil {
+			return nil, fmt.Errorf("unknown move %s", move)
+		}
+		if !contains(learnable, move) {
+			return nil, fmt.Errorf("%s can't learn %s", pokemon.Name, move)
+		}
+	}
+	if set.Item != "" {
+		if _, err := fetchItem(c, set.Item); err != nil {
+			return nil, fmt.Errorf("unknown item %s", set.Item)
+		}
+	}
+
+	caught, err := newCaughtPokemon(c, pokemon, set.Level)
+	if err != nil {
+		return nil, err
+	}
+	caught.HeldItem = set.Item
+	caught.Shiny = set.Shiny
+	caught.EVs = set.EVs
+	caught.IVs = set.IVs
+	// Showdown treats a set without a nature as Serious.
+	caught.Nature = "serious"
+	if set.Nature != "" {
+		caught.Nature = set.Nature
+	}
+	if set.Ability != "" {
+		caught.Ability = set.Ability
+	}
+	if len(set.Moves) > 0 {
+		caught.KnownMoves = set.Moves
+	}
+	return caught, nil
+}
+
+func commandImportShowdown(c *config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: import-showdown <file>")
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", args[1], err)
+	}
+	sets, err := parseShowdown(string(data))
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		return fmt.Errorf("%s has no pokemon in it", args[1])
+	}
+	// Check every set before adding any, so a bad paste imports nothing.
+	imported := []*CaughtPokemon{}
+	for _, set := range sets {
+		caught, err := caughtFromShowdown(c, set)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", set.Line, err)
+		}
+		if !confirmReplace(c, caught.Name) {
+			return fmt.Errorf("line %d: you kept your %s, nothing was imported", set.Line, caught.Name)
+		}
+		imported = append(imported, caught)
+	}
+	for _, caught := range imported {
+		markSeen(c, caught.Species.Name)
+		addCaught(c, caught)
+		fmt.Fprintf(c.Out, "Imported %s (Lv. %d)\n", caught.Name, caught.Level)
+	}
+	return nil
+}
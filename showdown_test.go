@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testPaste = `=== [gen4] Team ===
+
+Sparky (Pikachu) (M) @ Light Ball
+Ability: Static
+Level: 50
+Shiny: Yes
+Tera Type: Electric
+EVs: 4 HP / 252 Atk / 252 Spe
+Jolly Nature
+IVs: 0 SpA
+- Volt Tackle
+- U-turn
+
+Mr. Mime
+- Psychic
+`
+
+func TestParseShowdown(t *testing.T) {
+	sets, err := parseShowdown(testPaste)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("expected 2 sets, got %d", len(sets))
+	}
+	pikachu := sets[0]
+	if pikachu.Line != 3 || pikachu.Nickname != "Sparky" || pikachu.Species != "pikachu" || pikachu.Item != "light-ball" {
+		t.Errorf("unexpected header: %+v", pikachu)
+	}
+	if pikachu.Ability != "static" || pikachu.Level != 50 || !pikachu.Shiny || pikachu.Nature != "jolly" {
+		t.Errorf("unexpected details: %+v", pikachu)
+	}
+	if fmt.Sprint(pikachu.EVs) != "map[attack:252 hp:4 speed:252]" {
+		t.Errorf("unexpected EVs: %v", pikachu.EVs)
+	}
+	if pikachu.IVs["special-attack"] != 0 || pikachu.IVs["speed"] != maxIV {
+		t.Errorf("unexpected IVs: %v", pikachu.IVs)
+	}
+	if fmt.Sprint(pikachu.Moves) != "[volt-tackle u-turn]" {
+		t.Errorf("unexpected moves: %v", pikachu.Moves)
+	}
+	if mime := sets[1]; mime.Species != "mr-mime" || mime.Level != maxLevel || mime.Nature != "" {
+		t.Errorf("unexpected defaults: %+v", mime)
+	}
+}
+
+func TestParseShowdownErrors(t *testing.T) {
+	cases := []struct {
+		paste string
+		msg   string
+	}{
+		{paste: "Pikachu\nEVs: 252 Atk / 252 Spe / 252 HP", msg: "line 2: EVs add up to 756"},
+		{paste: "Pikachu\nIVs: 32 Atk", msg: "line 2: \"32\" must be a number from 0 to 31"},
+		{paste: "Pikachu\nEVs: 4 Luck", msg: "unknown stat"},
+		{paste: "Pikachu\nSilly Nature", msg: "unknown nature"},
+		{paste: "Pikachu\nLevel: 101", msg: "level must be"},
+		{paste: "\n\nPikachu\n- a\n- b\n- c\n- d\n- e", msg: "line 8: a pokemon can only know 4 moves"},
+		{paste: "Pikachu\nlooks cute", msg: "unexpected"},
+	}
+	for _, c := range cases {
+		_, err := parseShowdown(c.paste)
+		if err == nil || !strings.Contains(err.Error(), c.msg) {
+			t.Errorf("%q: expected an error containing %q, got %v", c.paste, c.msg, err)
+		}
+	}
+}
+
+func TestShowdownRoundTrip(t *testing.T) {
+	sets, err := parseShowdown(testPaste)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paste := formatShowdown(sets)
+	expected := "Sparky (Pikachu) @ Light Ball\nAbility: Static\nLevel: 50\nShiny: Yes\nEVs: 4 HP / 252 Atk / 252 Spe\nJolly Nature\nIVs: 0 SpA\n- Volt Tackle\n- U Turn\n\nMr-Mime\n- Psychic\n"
+	if paste != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, paste)
+	}
+	again, err := parseShowdown(paste)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(withoutLines(again)) != fmt.Sprint(withoutLines(sets)) {
+		t.Errorf("expected the paste to read back the same, got %+v", again)
+	}
+}
+
+func withoutLines(sets []showdownSet) []showdownSet {
+	result := []showdownSet{}
+	for _, set := range sets {
+		set.Line = 0
+		result = append(result, set)
+	}
+	return result
+}
+
+func TestShowdownSlug(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Mr. Mime":     "mr-mime",
+		"Farfetch’d":   "farfetchd",
+		"Nidoran♀":     "nidoran-f",
+		"King's Rock":  "kings-rock",
+		"Will-O-Wisp":  "will-o-wisp",
+		"Type: Null":   "type-null",
+		" Rotom-Wash ": "rotom-wash",
+	} {
+		if actual := showdownSlug(name); actual != expected {
+			t.Errorf("%q: expected %q, got %q", name, expected, actual)
+		}
+	}
+}
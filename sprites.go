@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strconv"
+
+	"github.com/glitchdawg/pokedex/internal/termimg"
+)
+
+// The sprite shown at the top of inspect is kept small.
+const (
+	inspectSpriteCols = 32
+	inspectSpriteRows = 16
+)
+
+// spriteURL picks the sprite of a pokemon to draw. gen 0 means the default
+// modern sprite; otherwise a representative game of that generation is used.
+func spriteURL(pokemon Pokemon, shiny, back bool, gen int) (string, error) {
+	s := pokemon.Sprites
+	v := s.Versions
+	type variants struct{ front, frontShiny, back, backShiny string }
+	var options variants
+	switch gen {
+	case 0:
+		options = variants{s.FrontDefault, s.FrontShiny, s.BackDefault, s.BackShiny}
+	case 1:
+		options = variants{front: v.GenerationI.RedBlue.FrontTransparent, back: v.GenerationI.RedBlue.BackTransparent}
+	case 2:
+		g := v.GenerationIi.Crystal
+		options = variants{g.FrontTransparent, g.FrontShinyTransparent, g.BackTransparent, g.BackShinyTransparent}
+	case 3:
+		g := v.GenerationIii.RubySapphire
+		options = variants{g.FrontDefault, g.FrontShiny, g.BackDefault, g.BackShiny}
+	case 4:
+		g := v.GenerationIv.Platinum
+		options = variants{g.FrontDefault, g.FrontShiny, g.BackDefault, g.BackShiny}
+	case 5:
+		g := v.GenerationV.BlackWhite
+		options = variants{g.FrontDefault, g.FrontShiny, g.BackDefault, g.BackShiny}
+	case 6:
+		g := v.GenerationVi.XY
+		options = variants{front: g.FrontDefault, frontShiny: g.FrontShiny}
+	case 7:
+		g := v.GenerationVii.UltraSunUltraMoon
+		options = variants{front: g.FrontDefault, frontShiny: g.FrontShiny}
+	case 8:
+		options = variants{front: v.GenerationViii.Icons.FrontDefault}
+	default:
+		return "", fmt.Errorf("there are no sprites for generation %d", gen)
+	}
+
+	url := options.front
+	switch {
+	case shiny && back:
+		url = options.backShiny
+	case shiny:
+		url = options.frontShiny
+	case back:
+		url = options.back
+	}
+	if url == "" {
+		return "", fmt.Errorf("no such sprite of %s", pokemon.Name)
+	}
+	return url, nil
+}
+
+// artworkURL is the full size artwork of a pokemon, drawn instead of its
+// sprite when the terminal can show real images.
+func artworkURL(pokemon Pokemon, shiny bool) string {
+	art, home := pokemon.Sprites.Other.OfficialArtwork, pokemon.Sprites.Other.Home
+	if shiny {
+		if art.FrontShiny != "" {
+			return art.FrontShiny
+		}
+		return home.FrontShiny
+	}
+	if art.FrontDefault != "" {
+		return art.FrontDefault
+	}
+	return home.FrontDefault
+}
+
+// drawImage downloads a PNG and draws it in the best way the terminal
+// supports, no larger than cols by rows cells. In text mode only the URL is
+// shown.
+func drawImage(c *config, url string, cols, rows int) (string, error) {
+	if url == "" {
+		return "", fmt.Errorf("no sprite available")
+	}
+	mode := graphicsMode(c)
+	if mode == termimg.ModeText {
+		return fmt.Sprintf("[image: %s]\n", url), nil
+	}
+	data, err := fetchBytes(c, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch sprite: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode sprite: %v", err)
+	}
+	switch mode {
+	case termimg.ModeKitty:
+		b := img.Bounds()
+		return termimg.Kitty(data, termimg.FitCells(b.Dx(), b.Dy(), cols, rows)), nil
+	case termimg.ModeSixel:
+		return termimg.Sixel(termimg.ScaleToCells(termimg.Crop(img), cols, rows)), nil
+	}
+	return termimg.HalfBlocks(termimg.Crop(img), cols, rows), nil
+}
+
+func commandSprite(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("please provide a pokemon name")
+	}
+	shiny, back, gen := false, false, 0
+	for i := 2; i < len(args); i++ {
+		switch args[i] {
+		case "--shiny":
+			shiny = true
+		case "--back":
+			back = true
+		case "--gen":
+			if i+1 == len(args) {
+				return fmt.Errorf("please provide a generation number")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return fmt.Errorf("please provide a valid generation number")
+			}
+			gen = n
+			i++
+		default:
+			return fmt.Errorf("unknown option: %s", args[i])
+		}
+	}
+	pokemon, err := fetchPokemon(c, args[1])
+	if err != nil {
+		return err
+	}
+	if caught, ok := c.Pokedex[pokemon.Name]; ok && caught.Shiny {
+		shiny = true
+	}
+	url, err := spriteURL(pokemon, shiny, back, gen)
+	if err != nil {
+		return err
+	}
+	mode := graphicsMode(c)
+	if gen == 0 && !back && (mode == termimg.ModeKitty || mode == termimg.ModeSixel) {
+		if artwork := artworkURL(pokemon, shiny); artwork != "" {
+			url = artwork
+		}
+	}
+	cols, rows := termimg.Size()
+	art, err := drawImage(c, url, cols-1, rows-2)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(c.Out, art)
+	return nil
+}
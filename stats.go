@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// statNames are the stats in the order games and Showdown list them.
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+const (
+	maxIV       = 31
+	maxEV       = 252
+	maxTotalEVs = 510
+)
+
+// nature raises one stat by 10% and lowers another; neutral natures have
+// neither.
+type nature struct {
+	increased string
+	decreased string
+}
+
+var natures = map[string]nature{
+	"hardy":   {},
+	"lonely":  {"attack", "defense"},
+	"brave":   {"attack", "speed"},
+	"adamant": {"attack", "special-attack"},
+	"naughty": {"attack", "special-defense"},
+	"bold":    {"defense", "attack"},
+	"docile":  {},
+	"relaxed": {"defense", "speed"},
+	"impish":  {"defense", "special-attack"},
+	"lax":     {"defense", "special-defense"},
+	"timid":   {"speed", "attack"},
+	"hasty":   {"speed", "defense"},
+	"serious": {},
+	"jolly":   {"speed", "special-attack"},
+	"naive":   {"speed", "special-defense"},
+	"modest":  {"special-attack", "attack"},
+	"mild":    {"special-attack", "defense"},
+	"quiet":   {"special-attack", "speed"},
+	"bashful": {},
+	"rash":    {"special-attack", "special-defense"},
+	"calm":    {"special-defense", "attack"},
+	"gentle":  {"special-defense", "defense"},
+	"sassy":   {"special-defense", "speed"},
+	"careful": {"special-defense", "special-attack"},
+	"quirky":  {},
+}
+
+// calcStat returns the value of a stat with the mainline formula.
+func calcStat(stat string, base, iv, ev, level int, natureName string) int {
+	value := (2*base + iv + ev/4) * level / 100
+	if stat == "hp" {
+		return value + level + 10
+	}
+	value += 5
+	n := natures[natureName]
+	switch stat {
+	case n.increased:
+		value = value * 110 / 100
+	case n.decreased:
+		value = value * 90 / 100
+	}
+	return value
+}
+
+// calcStats returns every stat of a pokemon keyed by stat name. Missing IVs
+// and EVs count as zero.
+func calcStats(pokemon Pokemon, level int, ivs, evs map[string]int, natureName string) map[string]int {
+	stats := make(map[string]int)
+	for _, stat := range pokemon.Stats {
+		name := stat.Stat.Name
+		stats[name] = calcStat(name, stat.BaseStat, ivs[name], evs[name], level, natureName)
+	}
+	return stats
+}
+
+// checkSpreads reports IVs or EVs that no pokemon could have.
+func checkSpreads(ivs, evs map[string]int) error {
+	for stat, iv := range ivs {
+		if !contains(statNames, stat) || iv < 0 || iv > maxIV {
+			return fmt.Errorf("%s IV must be from 0 to %d", stat, maxIV)
+		}
+	}
+	total := 0
+	for stat, ev := range evs {
+		if !contains(statNames, stat) || ev < 0 || ev > maxEV {
+			return fmt.Errorf("%s EVs must be from 0 to %d", stat, maxEV)
+		}
+		total += ev
+	}
+	if total > maxTotalEVs {
+		return fmt.Errorf("EVs add up to %d, more than %d", total, maxTotalEVs)
+	}
+	return nil
+}
+
+// currentStats are the actual stats of a caught pokemon at its level.
+func (p *CaughtPokemon) currentStats() map[string]int {
+	return calcStats(p.Pokemon, p.Level, p.IVs, p.EVs, p.Nature)
+}
+
+func rollIVs() map[string]int {
+	ivs := make(map[string]int)
+	for _, stat := range statNames {
+		ivs[stat] = rand.Intn(maxIV + 1)
+	}
+	return ivs
+}
+
+func rollNature() string {
+	names := make([]string, 0, len(natures))
+	for name := range natures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names[rand.Intn(len(names))]
+}
+
+// addEffortValues awards the EV yield of a defeated pokemon, respecting the
+// per-stat and total caps, and returns what was actually gained.
+func addEffortValues(p *CaughtPokemon, defeated Pokemon) map[string]int {
+	if p.EVs == nil {
+		p.EVs = make(map[string]int)
+	}
+	total := 0
+	for _, ev := range p.EVs {
+		total += ev
+	}
+	gained := make(map[string]int)
+	for _, stat := range defeated.Stats {
+		name := stat.Stat.Name
+		gain := min(stat.Effort, maxEV-p.EVs[name], maxTotalEVs-total)
+		if gain <= 0 {
+			continue
+		}
+		p.EVs[name] += gain
+		total += gain
+		gained[name] = gain
+	}
+	return gained
+}
+
+func natureDescription(name string) string {
+	n, ok := natures[name]
+	if !ok || n.increased == "" {
+		return name
+	}
+	return fmt.Sprintf("%s: +%s -%s", name, n.increased, n.decreased)
+}
+
+func printStatTable(w io.Writer, pokemon Pokemon, level int, ivs, evs map[string]int, natureName string) {
+	stats := calcStats(pokemon, level, ivs, evs, natureName)
+	n := natures[natureName]
+	fmt.Fprintf(w, "%-16s %4s %3s %3s %5s\n", "stat", "base", "IV", "EV", "value")
+	for _, stat := range pokemon.Stats {
+		name := stat.Stat.Name
+		mark := ""
+		if name == n.increased {
+			mark = "+"
+		} else if name == n.decreased {
+			mark = "-"
+		}
+		fmt.Fprintf(w, "%-16s %4d %3d %3d %5d%s\n", name, stat.BaseStat, ivs[name], evs[name], stats[name], mark)
+	}
+}
+
+func commandStats(c *config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("please provide the caught ID or name of a pokemon")
+	}
+	pokemon, err := findCaught(c, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "#%d %s Lv. %d (%s)\n", pokemon.CaughtID, pokemon.Name, pokemon.Level, natureDescription(pokemon.Nature))
+	printStatTable(c.Out, pokemon.Pokemon, pokemon.Level, pokemon.IVs, pokemon.EVs, pokemon.Nature)
+	return nil
+}
+
+// parseSpread parses six slash separated values in the order hp, attack,
+// defense, special-attack, special-defense, speed.
+func parseSpread(value string, max int) (map[string]int, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != len(statNames) {
+		return nil, fmt.Errorf("expected %d values separated by /, e.g. 31/31/31/31/31/31", len(statNames))
+	}
+	spread := make(map[string]int)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n > max {
+			return nil, fmt.Errorf("%s must be a number from 0 to %d", statNames[i], max)
+		}
+		spread[statNames[i]] = n
+	}
+	return spread, nil
+}
+
+func commandStatCalc(c *config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: statcalc <pokemon> [--level <n>] [--nature <name>] [--ivs hp/atk/def/spa/spd/spe] [--evs hp/atk/def/spa/spd/spe]")
+	}
+	level, natureName := 50, "hardy"
+	ivs, evs := make(map[string]int), make(map[string]int)
+	for _, stat := range statNames {
+		ivs[stat] = maxIV
+	}
+	for i := 2; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return fmt.Errorf("%s needs a value", args[i])
+		}
+		value := args[i+1]
+		var err error
+		switch args[i] {
+		case "--level":
+			level, err = strconv.Atoi(value)
+			if err != nil || level < 1 || level > maxLevel {
+				return fmt.Errorf("level must be a number from 1 to %d", maxLevel)
+			}
+		case "--nature":
+			if _, ok := natures[value]; !ok {
+				return fmt.Errorf("unknown nature: %s", value)
+			}
+			natureName = value
+		case "--ivs":
+			if ivs, err = parseSpread(value, maxIV); err != nil {
+				return err
+			}
+		case "--evs":
+			if evs, err = parseSpread(value, maxEV); err != nil {
+				return err
+			}
+			total := 0
+			for _, ev := range evs {
+				total += ev
+			}
+			if total > maxTotalEVs {
+				return fmt.Errorf("EVs can add up to at most %d", maxTotalEVs)
+			}
+		default:
+			return fmt.Errorf("unknown option: %s", args[i])
+		}
+	}
+	pokemon, err := fetchPokemon(c, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "%s Lv. %d (%s)\n", pokemon.Name, level, natureDescription(natureName))
+	printStatTable(c.Out, pokemon, level, ivs, evs, natureName)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCalcStat(t *testing.T) {
+	cases := []struct {
+		stat   string
+		base   int
+		iv     int
+		ev     int
+		level  int
+		nature string
+		value  int
+	}{
+		{stat: "hp", base: 35, iv: 31, ev: 0, level: 50, nature: "jolly", value: 110},
+		{stat: "speed", base: 90, iv: 31, ev: 252, level: 50, nature: "jolly", value: 156},
+		{stat: "special-attack", base: 50, iv: 31, ev: 0, level: 50, nature: "jolly", value: 63},
+		{stat: "attack", base: 55, iv: 0, ev: 0, level: 5, nature: "hardy", value: 10},
+		{stat: "hp", base: 255, iv: 31, ev: 252, level: 100, nature: "hardy", value: 714},
+	}
+	for _, c := range cases {
+		if actual := calcStat(c.stat, c.base, c.iv, c.ev, c.level, c.nature); actual != c.value {
+			t.Errorf("%s base %d at %d (%s): expected %d, got %d", c.stat, c.base, c.level, c.nature, c.value, actual)
+		}
+	}
+}
+
+func TestAddEffortValuesCaps(t *testing.T) {
+	defeated := testCaught(t, 130, "gyarados", 2350, []string{"water"}, map[string]int{"attack": 125}).Pokemon
+	defeated.Stats[0].Effort = 2
+
+	pokemon := &CaughtPokemon{EVs: map[string]int{"attack": 251}}
+	gained := addEffortValues(pokemon, defeated)
+	if gained["attack"] != 1 || pokemon.EVs["attack"] != maxEV {
+		t.Errorf("expected attack EVs to stop at %d, gained %v", maxEV, gained)
+	}
+
+	pokemon = &CaughtPokemon{EVs: map[string]int{"speed": 252, "hp": 252, "defense": 5}}
+	gained = addEffortValues(pokemon, defeated)
+	if gained["attack"] != 1 || pokemon.EVs["attack"] != 1 {
+		t.Errorf("expected EVs to stop at a total of %d, gained %v", maxTotalEVs, gained)
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+const (
+	teamSize = 6
+	// exhaustiveLimit is how many teams are scored one by one before the
+	// search falls back to greedy picking and swapping.
+	exhaustiveLimit = 200000
+
+	coverageWeight       = 10.0
+	sharedWeaknessWeight = 15.0
+	statTotalWeight      = 1.0 / 60
+)
+
+// teamCandidate is a caught pokemon reduced to what the team search needs.
+// weak and covers are bit sets over the type chart's types.
+type teamCandidate struct {
+	name   string
+	weak   uint32
+	covers uint32
+	total  int
+}
+
+type teamScore struct {
+	score          float64
+	covered        uint32
+	sharedWeakness int
+	total          int
+}
+
+// scoreTeam rewards types hit super effectively and base stat total, and
+// punishes every extra member sharing a weakness.
+func scoreTeam(team []teamCandidate, typeCount int) teamScore {
+	s := teamScore{}
+	for _, member := range team {
+		s.covered |= member.covers
+		s.total += member.total
+	}
+	for t := 0; t < typeCount; t++ {
+		weak := 0
+		for _, member := range team {
+			if member.weak&(1<<t) != 0 {
+				weak++
+			}
+		}
+		if weak > 1 {
+			s.sharedWeakness += weak - 1
+		}
+	}
+	s.score = coverageWeight*float64(bits.OnesCount32(s.covered)) - sharedWeaknessWeight*float64(s.sharedWeakness) + statTotalWeight*float64(s.total)
+	return s
+}
+
+func combinations(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1
+	for i := 1; i <= k; i++ {
+		result = result * (n - k + i) / i
+		if result > exhaustiveLimit {
+			return result
+		}
+	}
+	return result
+}
+
+// bestTeam picks up to teamSize pokemon: all of required plus the
+// candidates that score best with them.
+func bestTeam(required, candidates []teamCandidate, typeCount int) []teamCandidate {
+	slots := min(teamSize-len(required), len(candidates))
+	if slots <= 0 {
+		return required
+	}
+	if combinations(len(candidates), slots) <= exhaustiveLimit {
+		return exhaustiveTeam(required, candidates, slots, typeCount)
+	}
+	return improvedTeam(required, candidates, slots, typeCount)
+}
+
+func exhaustiveTeam(required, candidates []teamCandidate, slots, typeCount int) []teamCandidate {
+	var best []teamCandidate
+	bestScore := 0.0
+	team := append([]teamCandidate{}, required...)
+	var pick func(start int)
+	pick = func(start int) {
+		if len(team) == len(required)+slots {
+			if s := scoreTeam(team, typeCount).score; best == nil || s > bestScore {
+				best, bestScore = append([]teamCandidate{}, team...), s
+			}
+			return
+		}
+		for i := start; i < len(candidates); i++ {
+			team = append(team, candidates[i])
+			pick(i + 1)
+			team = team[:len(team)-1]
+		}
+	}
+	pick(0)
+	return best
+}
+
+// improvedTeam greedily fills the team and then swaps members for unused
+// candidates for as long as that raises the score.
+func improvedTeam(required, candidates []teamCandidate, slots, typeCount int) []teamCandidate {
+	team := append([]teamCandidate{}, required...)
+	used := make([]bool, len(candidates))
+	// chosen[i] is the candidate in team slot len(required)+i.
+	chosen := make([]int, 0, slots)
+	for len(team) < len(required)+slots {
+		bestIndex, bestScore := -1, 0.0
+		for i, candidate := range candidates {
+			if used[i] {
+				continue
+			}
+			if s := scoreTeam(append(team, candidate), typeCount).score; bestIndex < 0 || s > bestScore {
+				bestIndex, bestScore = i, s
+			}
+		}
+		used[bestIndex] = true
+		chosen = append(chosen, bestIndex)
+		team = append(team, candidates[bestIndex])
+	}
+
+	for improved := true; improved; {
+		improved = false
+		current := scoreTeam(team, typeCount).score
+		for slot := len(required); slot < len(team); slot++ {
+			for i, candidate := range candidates {
+				if used[i] {
+					continue
+				}
+				previous := team[slot]
+				team[slot] = candidate
+				if scoreTeam(team, typeCount).score > current {
+					used[chosen[slot-len(required)]] = false
+					used[i] = true
+					chosen[slot-len(required)] = i
+					improved = true
+					break
+				}
+				team[slot] = previous
+			}
+			if improved {
+				break
+			}
+		}
+	}
+	return team
+}
+
+func typesIn(set uint32, types []string) []string {
+	names := []string{}
+	for i, t := range types {
+		if set&(1<<i) != 0 {
+			names = append(names, t)
+		}
+	}
+	return names
+}
+
+func newTeamCandidate(c *config, chart typeChart, pokemon *CaughtPokemon) (teamCandidate, error) {
+	moveTypes, err := damagingMoveTypes(c, pokemon)
+	if err != nil {
+		return teamCandidate{}, err
+	}
+	return chart.teamCandidate(pokemon, moveTypes), nil
+}
+
+func (chart typeChart) teamCandidate(pokemon *CaughtPokemon, moveTypes []string) teamCandidate {
+	candidate := teamCandidate{name: pokemon.Name}
+	for _, stat := range pokemon.Stats {
+		candidate.total += stat.BaseStat
+	}
+	for i, t := range chart.types {
+		if chart.against(t, typeNames(pokemon.Pokemon)) > 1 {
+			candidate.weak |= 1 << i
+		}
+		for _, attack := range moveTypes {
+			if chart.against(attack, []string{t}) > 1 {
+				candidate.covers |= 1 << i
+			}
+		}
+	}
+	return candidate
+}
+
+func commandSuggestTeam(c *config, args []string) error {
+	mustInclude := []string{}
+	noLegendaries := false
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--must-include":
+			if i+1 == len(args) {
+				return fmt.Errorf("--must-include needs a pokemon")
+			}
+			mustInclude = append(mustInclude, args[i+1])
+			i++
+		case "--no-legendaries":
+			noLegendaries = true
+		default:
+			return fmt.Errorf("unknown option: %s", args[i])
+		}
+	}
+	if len(mustInclude) > teamSize {
+		return fmt.Errorf("a team only has room for %d pokemon", teamSize)
+	}
+	chart, err := fetchTypeChart(c)
+	if err != nil {
+		return err
+	}
+
+	required, candidates := []teamCandidate{}, []teamCandidate{}
+	picked := make(map[string]bool)
+	for _, ref := range mustInclude {
+		pokemon, err := findCaught(c, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %v", ref, err)
+		}
+		if picked[pokemon.Name] {
+			continue
+		}
+		candidate, err := newTeamCandidate(c, chart, pokemon)
+		if err != nil {
+			return err
+		}
+		picked[pokemon.Name] = true
+		required = append(required, candidate)
+	}
+	for _, pokemon := range (pokedexQuery{sortBy: "id"}).apply(c.Pokedex) {
+		if picked[pokemon.Name] || (noLegendaries && (pokemon.Legendary || pokemon.Mythical)) {
+			continue
+		}
+		candidate, err := newTeamCandidate(c, chart, pokemon)
+		if err != nil {
+			return err
+		}
+		candidates = append(candidates, candidate)
+	}
+	if len(required)+len(candidates) == 0 {
+		return fmt.Errorf("you have no pokemon to build a team from")
+	}
+
+	team := bestTeam(required, candidates, len(chart.types))
+	s := scoreTeam(team, len(chart.types))
+	fmt.Fprintln(c.Out, "Suggested team:")
+	for _, member := range team {
+		pokemon := c.Pokedex[member.name]
+		fmt.Fprintf(c.Out, "- %s (%s, base stat total %d)\n", member.name, strings.Join(typeNames(pokemon.Pokemon), "/"), member.total)
+	}
+	fmt.Fprintf(c.Out, "Hits %d of %d types super effectively\n", bits.OnesCount32(s.covered), len(chart.types))
+	if uncovered := typesIn(^s.covered, chart.types); len(uncovered) > 0 {
+		fmt.Fprintf(c.Out, "Uncovered types: %s\n", strings.Join(uncovered, ", "))
+	}
+	shared := []string{}
+	for i, t := range chart.types {
+		weak := 0
+		for _, member := range team {
+			if member.weak&(1<<i) != 0 {
+				weak++
+			}
+		}
+		if weak > 1 {
+			shared = append(shared, fmt.Sprintf("%s (%d)", t, weak))
+		}
+	}
+	fmt.Fprintf(c.Out, "Shared weaknesses: %s\n", orNone(strings.Join(shared, ", ")))
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func testCandidate(t *testing.T, chart typeChart, name string, total int, types, moveTypes []string) teamCandidate {
+	t.Helper()
+	return chart.teamCandidate(testCaught(t, 1, name, 10, types, map[string]int{"hp": total}), moveTypes)
+}
+
+func teamNames(team []teamCandidate) []string {
+	result := []string{}
+	for _, member := range team {
+		result = append(result, member.name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestScoreTeam(t *testing.T) {
+	chart := testChart()
+	charmander := testCandidate(t, chart, "charmander", 309, []string{"fire"}, []string{"fire"})
+	vulpix := testCandidate(t, chart, "vulpix", 299, []string{"fire"}, []string{"fire"})
+	squirtle := testCandidate(t, chart, "squirtle", 314, []string{"water"}, []string{"water"})
+
+	s := scoreTeam([]teamCandidate{charmander, vulpix}, len(chart.types))
+	if s.sharedWeakness != 3 {
+		// fire is weak to water, ground and rock
+		t.Errorf("expected 3 shared weaknesses, got %d", s.sharedWeakness)
+	}
+	if s.total != 608 {
+		t.Errorf("expected a base stat total of 608, got %d", s.total)
+	}
+	if a, b := scoreTeam([]teamCandidate{charmander, squirtle}, len(chart.types)), s; a.score <= b.score {
+		t.Errorf("expected charmander and squirtle (%g) to beat two fire types (%g)", a.score, b.score)
+	}
+}
+
+func TestBestTeam(t *testing.T) {
+	chart := testChart()
+	candidates := []teamCandidate{
+		testCandidate(t, chart, "charmander", 309, []string{"fire"}, []string{"fire"}),
+		testCandidate(t, chart, "vulpix", 299, []string{"fire"}, []string{"fire"}),
+		testCandidate(t, chart, "ponyta", 410, []string{"fire"}, []string{"fire"}),
+		testCandidate(t, chart, "squirtle", 314, []string{"water"}, []string{"water"}),
+		testCandidate(t, chart, "bulbasaur", 318, []string{"grass"}, []string{"grass"}),
+		testCandidate(t, chart, "pikachu", 320, []string{"electric"}, []string{"electric"}),
+		testCandidate(t, chart, "geodude", 300, []string{"rock", "ground"}, []string{"rock", "ground"}),
+		testCandidate(t, chart, "pidgey", 251, []string{"normal", "flying"}, []string{"flying"}),
+	}
+	team := bestTeam(nil, candidates, len(chart.types))
+	expected := []string{"bulbasaur", "geodude", "pidgey", "pikachu", "ponyta", "squirtle"}
+	if actual := teamNames(team); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	team = bestTeam(candidates[1:2], append(candidates[:1:1], candidates[2:]...), len(chart.types))
+	if team[0].name != "vulpix" || len(team) != teamSize {
+		t.Errorf("expected a full team led by the required vulpix, got %v", teamNames(team))
+	}
+
+	if greedy := improvedTeam(nil, candidates, teamSize, len(chart.types)); scoreTeam(greedy, len(chart.types)).score < scoreTeam(team, len(chart.types)).score {
+		t.Errorf("expected the greedy search to find at least as good a team, got %v", teamNames(greedy))
+	}
+}
+
+func TestImprovedTeamHasNoDuplicates(t *testing.T) {
+	chart := testChart()
+	rng := rand.New(rand.NewSource(1))
+	randomTypes := func(n int) []string {
+		types := []string{}
+		for i := 0; i < n; i++ {
+			types = append(types, chart.types[rng.Intn(len(chart.types))])
+		}
+		return types
+	}
+	for trial := 0; trial < 200; trial++ {
+		candidates := []teamCandidate{}
+		for i := 0; i < 12; i++ {
+			candidates = append(candidates, testCandidate(t, chart, fmt.Sprintf("mon%d", i), 200+rng.Intn(400), randomTypes(1+rng.Intn(2)), randomTypes(1+rng.Intn(3))))
+		}
+		team := improvedTeam(nil, candidates, teamSize, len(chart.types))
+		seen := map[string]bool{}
+		for _, member := range team {
+			if seen[member.name] {
+				t.Fatalf("trial %d: %s is on the team twice: %v", trial, member.name, teamNames(team))
+			}
+			seen[member.name] = true
+		}
+	}
+}
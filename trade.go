@@ -0,0 +1,564 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A trade takes three codes. The sender offers a pokemon to one trainer, the
+// receiver accepts the offer, and the sender confirms the acceptance with a
+// release, the only code a pokemon can be received from. Each prefix names
+// the kind of code and its format version.
+const (
+	tradeOfferPrefix   = "pkx2"
+	tradeAcceptPrefix  = "pka1"
+	tradeReleasePrefix = "pkr1"
+)
+
+var tradeCodeKinds = map[string]string{
+	tradeOfferPrefix:   "trade offer",
+	tradeAcceptPrefix:  "trade acceptance",
+	tradeReleasePrefix: "trade release",
+}
+
+// maxTradeSize bounds how much a trade code may inflate to.
+const maxTradeSize = 64 << 10
+
+// tradeEncoding is lowercase so codes survive the REPL lowercasing its input.
+var tradeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// tradedPokemon is what a trade carries. The receiver fetches everything else
+// about the species from PokeAPI, which keeps codes short.
+type tradedPokemon struct {
+	Name       string         `json:"n"`
+	Level      int            `json:"l"`
+	Experience int            `json:"x"`
+	GrowthRate string         `json:"g"`
+	KnownMoves []string       `json:"m"`
+	Ability    string         `json:"a"`
+	HeldItem   string         `json:"h,omitempty"`
+	Shiny      bool           `json:"s,omitempty"`
+	CaughtAt   time.Time      `json:"c"`
+	Legendary  bool           `json:"lg,omitempty"`
+	Mythical   bool           `json:"my,omitempty"`
+	IVs        map[string]int `json:"i"`
+	EVs        map[string]int `json:"e"`
+	Nature     string         `json:"na"`
+}
+
+// tradeOffer is what offers and releases carry. To is the key of the only
+// trainer who can accept it.
+type tradeOffer struct {
+	ID        string            `json:"id"`
+	OfferedAt time.Time         `json:"t"`
+	To        ed25519.PublicKey `json:"to"`
+	Pokemon   tradedPokemon     `json:"p"`
+}
+
+// tradeAcceptance carries the offer code being accepted, so the sender can
+// check it is one of theirs and was made out to whoever signed the
+// acceptance.
+type tradeAcceptance struct {
+	Offer string `json:"o"`
+}
+
+func newTrainerKey() ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// trainerID is a short fingerprint of a trainer's public key.
+func trainerID(key ed25519.PublicKey) string {
+	return hex.EncodeToString(key[:4])
+}
+
+func myTrainerID(c *config) string {
+	return trainerID(c.TrainerKey.Public().(ed25519.PublicKey))
+}
+
+func myTrainerKey(c *config) ed25519.PublicKey {
+	return c.TrainerKey.Public().(ed25519.PublicKey)
+}
+
+// parseTrainerKey reads a public key as trade shows it, in hex. Its first
+// eight digits are the trainer's ID.
+func parseTrainerKey(s string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%q is not a trainer key, ask the trainer to run trade to see theirs", s)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func newTradeID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func tradedFrom(p *CaughtPokemon) tradedPokemon {
+	return tradedPokemon{
+		Name:       p.Name,
+		Level:      p.Level,
+		Experience: p.Experience,
+		GrowthRate: p.GrowthRate,
+		KnownMoves: p.KnownMoves,
+		Ability:    p.Ability,
+		HeldItem:   p.HeldItem,
+		Shiny:      p.Shiny,
+		CaughtAt:   p.CaughtAt,
+		Legendary:  p.Legendary,
+		Mythical:   p.Mythical,
+		IVs:        p.IVs,
+		EVs:        p.EVs,
+		Nature:     p.Nature,
+	}
+}
+
+// validate checks what a trade carries against the limits the games have,
+// the same ones a Showdown paste is held to. A valid signature only says who
+// made a code, not that they played fair.
+func (t tradedPokemon) validate() error {
+	if t.Level < 1 || t.Level > maxLevel {
+		return fmt.Errorf("level must be from 1 to %d", maxLevel)
+	}
+	if _, ok := natures[t.Nature]; !ok {
+		return fmt.Errorf("unknown nature %s", t.Nature)
+	}
+	if len(t.KnownMoves) > 4 {
+		return errors.New("a pokemon knows at most 4 moves")
+	}
+	return checkSpreads(t.IVs, t.EVs)
+}
+
+// caughtFromTrade checks a traded pokemon against PokeAPI and builds the
+// caught record for it. What the species decides, like its growth rate and
+// whether it is legendary, comes from PokeAPI rather than the code.
+func caughtFromTrade(c *config, t tradedPokemon) (*CaughtPokemon, error) {
+	pokemon, err := fetchPokemon(c, t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown species %s", t.Name)
+	}
+	if !contains(abilityNames(pokemon), t.Ability) {
+		return nil, fmt.Errorf("%s can't have the ability %s", pokemon.Name, t.Ability)
+	}
+	if err := checkLearnable(pokemon, t.KnownMoves); err != nil {
+		return nil, err
+	}
+	if t.HeldItem != "" {
+		if _, err := fetchItem(c, t.HeldItem); err != nil {
+			return nil, fmt.Errorf("unknown item %s", t.HeldItem)
+		}
+	}
+	species, err := fetchSpecies(c, pokemon)
+	if err != nil {
+		return nil, err
+	}
+	growth, err := fetchGrowthRate(c, species.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+	if t.Experience < experienceForLevel(growth, t.Level) || (t.Level < maxLevel && t.Experience >= experienceForLevel(growth, t.Level+1)) {
+		return nil, fmt.Errorf("%d Exp. Points don't make level %d", t.Experience, t.Level)
+	}
+	caught := &CaughtPokemon{
+		Pokemon:    pokemon,
+		Level:      t.Level,
+		Experience: t.Experience,
+		GrowthRate: growth.Name,
+		KnownMoves: t.KnownMoves,
+		Ability:    t.Ability,
+		HeldItem:   t.HeldItem,
+		Shiny:      t.Shiny,
+		CaughtAt:   t.CaughtAt,
+		Legendary:  species.IsLegendary,
+		Mythical:   species.IsMythical,
+		IVs:        t.IVs,
+		EVs:        t.EVs,
+		Nature:     t.Nature,
+	}
+	if caught.EVs == nil {
+		caught.EVs = make(map[string]int)
+	}
+	return caught, nil
+}
+
+// sealTrade packs v into a code: the signer's public key, a signature and
+// the compressed JSON of v. The compressed bytes are what is signed, so
+// nothing untrusted is inflated before it is verified.
+func sealTrade(key ed25519.PrivateKey, prefix string, v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %v", tradeCodeKinds[prefix], err)
+	}
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	w.Write(data)
+	w.Close()
+	packed := append([]byte{}, key.Public().(ed25519.PublicKey)...)
+	packed = append(packed, ed25519.Sign(key, compressed.Bytes())...)
+	packed = append(packed, compressed.Bytes()...)
+	return prefix + tradeEncoding.EncodeToString(packed), nil
+}
+
+// openTrade checks a code's signature and unpacks it into v, returning the
+// key of the trainer who signed it.
+func openTrade(code, prefix string, v interface{}) (ed25519.PublicKey, error) {
+	kind := tradeCodeKinds[prefix]
+	code = strings.ToLower(strings.Join(strings.Fields(code), ""))
+	if !strings.HasPrefix(code, prefix) {
+		for other, otherKind := range tradeCodeKinds {
+			if strings.HasPrefix(code, other) {
+				return nil, fmt.Errorf("this is a %s, not a %s", otherKind, kind)
+			}
+		}
+		return nil, fmt.Errorf("not a %s code", kind)
+	}
+	packed, err := tradeEncoding.DecodeString(strings.TrimPrefix(code, prefix))
+	if err != nil || len(packed) < ed25519.PublicKeySize+ed25519.SignatureSize {
+		return nil, fmt.Errorf("the %s code is damaged", kind)
+	}
+	key := ed25519.PublicKey(packed[:ed25519.PublicKeySize])
+	signature := packed[ed25519.PublicKeySize : ed25519.PublicKeySize+ed25519.SignatureSize]
+	compressed := packed[ed25519.PublicKeySize+ed25519.SignatureSize:]
+	if !ed25519.Verify(key, compressed, signature) {
+		return nil, fmt.Errorf("the %s code has been tampered with", kind)
+	}
+	data, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxTradeSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", kind, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", kind, err)
+	}
+	return key, nil
+}
+
+func encodeTrade(key ed25519.PrivateKey, offer tradeOffer) (string, error) {
+	return sealTrade(key, tradeOfferPrefix, offer)
+}
+
+// decodeTrade unpacks an offer code, returning the offer and the key of the
+// trainer who made it.
+func decodeTrade(code string) (tradeOffer, ed25519.PublicKey, error) {
+	return decodeOffer(code, tradeOfferPrefix)
+}
+
+func decodeOffer(code, prefix string) (tradeOffer, ed25519.PublicKey, error) {
+	offer := tradeOffer{}
+	key, err := openTrade(code, prefix, &offer)
+	if err != nil {
+		return offer, nil, err
+	}
+	if offer.ID == "" || offer.Pokemon.Name == "" {
+		return offer, nil, errors.New("the trade has no pokemon in it")
+	}
+	if len(offer.To) != ed25519.PublicKeySize {
+		return offer, nil, errors.New("the trade isn't made out to anyone")
+	}
+	if err := offer.Pokemon.validate(); err != nil {
+		return offer, nil, fmt.Errorf("the trade is not a legal pokemon: %v", err)
+	}
+	return offer, key, nil
+}
+
+func fetchEvolutionChain(c *config, species PokemonSpecies) (EvolutionChain, error) {
+	chain := EvolutionChain{}
+	if err := fetchJSON(c, species.EvolutionChain.URL, &chain); err != nil {
+		return chain, fmt.Errorf("failed to fetch evolution chain of %s: %v", species.Name, err)
+	}
+	return chain, nil
+}
+
+// tradeEvolution finds what a species evolves into when traded holding
+// heldItem, and whether the evolution uses up the item. Evolutions that
+// depend on the species it is traded for can't happen in a one-way trade.
+func tradeEvolution(link EvolutionLink, species, heldItem string) (into string, consumesItem bool, ok bool) {
+	if link.Species.Name != species {
+		for _, next := range link.EvolvesTo {
+			if into, consumesItem, ok = tradeEvolution(next, species, heldItem); ok {
+				return into, consumesItem, ok
+			}
+		}
+		return "", false, false
+	}
+	if heldItem == "everstone" {
+		return "", false, false
+	}
+	for _, next := range link.EvolvesTo {
+		for _, detail := range next.EvolutionDetails {
+			if detail.Trigger.Name != "trade" || detail.TradeSpecies != nil {
+				continue
+			}
+			if detail.HeldItem == nil {
+				return next.Species.Name, false, true
+			}
+			if detail.HeldItem.Name == heldItem {
+				return next.Species.Name, true, true
+			}
+		}
+	}
+	return "", false, false
+}
+
+// evolveOnTrade evolves a pokemon that has just arrived by trade if its
+// species evolves that way.
+func evolveOnTrade(c *config, p *CaughtPokemon) error {
+	species, err := fetchSpecies(c, p.Pokemon)
+	if err != nil {
+		return err
+	}
+	chain, err := fetchEvolutionChain(c, species)
+	if err != nil {
+		return err
+	}
+	into, consumesItem, ok := tradeEvolution(chain.Chain, species.Name, p.HeldItem)
+	if !ok {
+		return nil
+	}
+	evolved, err := fetchPokemon(c, into)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "What? %s is evolving!\n", p.Name)
+	if consumesItem {
+		p.HeldItem = ""
+	}
+	if !contains(abilityNames(evolved), p.Ability) {
+		p.Ability = rollAbility(evolved)
+	}
+	p.Pokemon = evolved
+	markSeen(c, evolved.Species.Name)
+	fmt.Fprintf(c.Out, "Congratulations! It evolved into %s!\n", evolved.Name)
+	return nil
+}
+
+// readTradeCode takes a code as typed or the name of a file holding one.
+func readTradeCode(arg string) string {
+	if data, err := os.ReadFile(arg); err == nil {
+		return string(data)
+	}
+	return arg
+}
+
+// giveTradeCode writes code to path if there is one, and prints it
+// otherwise.
+func giveTradeCode(c *config, code, path string) error {
+	if path == "" {
+		fmt.Fprintln(c.Out, code)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(code+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	fmt.Fprintf(c.Out, "The code is in %s\n", path)
+	return nil
+}
+
+// optionalArg is args[i], or empty if there are fewer arguments.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func commandTradeOffer(c *config, args []string) error {
+	if len(args) < 4 || len(args) > 5 {
+		return fmt.Errorf("usage: trade offer <caught-id> <trainer-key> [file]")
+	}
+	pokemon, err := findCaught(c, args[2])
+	if err != nil {
+		return err
+	}
+	to, err := parseTrainerKey(args[3])
+	if err != nil {
+		return err
+	}
+	if to.Equal(myTrainerKey(c)) {
+		return fmt.Errorf("you can't trade with yourself")
+	}
+	offer := tradeOffer{ID: newTradeID(), OfferedAt: time.Now(), To: to, Pokemon: tradedFrom(pokemon)}
+	code, err := encodeTrade(c.TrainerKey, offer)
+	if err != nil {
+		return err
+	}
+	// The pokemon leaves the Pokedex now so it can't be offered twice. It
+	// waits in the pending trades until the offer is confirmed or cancelled.
+	delete(c.Pokedex, pokemon.Name)
+	removeFromParty(c, pokemon.Name)
+	c.PendingTrades[offer.ID] = pokemon
+	fmt.Fprintf(c.Out, "Offered %s to trainer %s as trade %s. Send them this code:\n", pokemon.Name, trainerID(to), offer.ID)
+	return giveTradeCode(c, code, optionalArg(args, 4))
+}
+
+func commandTradeAccept(c *config, args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return fmt.Errorf("usage: trade accept <code|file> [file]")
+	}
+	code := readTradeCode(args[2])
+	offer, from, err := decodeTrade(code)
+	if err != nil {
+		return err
+	}
+	if from.Equal(myTrainerKey(c)) {
+		return fmt.Errorf("this is your own offer, use trade cancel %s to take %s back", offer.ID, offer.Pokemon.Name)
+	}
+	if !offer.To.Equal(myTrainerKey(c)) {
+		return fmt.Errorf("trade %s is for trainer %s, not you", offer.ID, trainerID(offer.To))
+	}
+	if c.AcceptedTrades[offer.ID] {
+		return fmt.Errorf("you have already received trade %s", offer.ID)
+	}
+	if _, err := caughtFromTrade(c, offer.Pokemon); err != nil {
+		return fmt.Errorf("the trade is not a legal pokemon: %v", err)
+	}
+	acceptance, err := sealTrade(c.TrainerKey, tradeAcceptPrefix, tradeAcceptance{Offer: strings.Join(strings.Fields(code), "")})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Accepted %s (Lv. %d) from trainer %s. Send them this code, and receive the code they send back with trade receive:\n", offer.Pokemon.Name, offer.Pokemon.Level, trainerID(from))
+	return giveTradeCode(c, acceptance, optionalArg(args, 3))
+}
+
+// commandTradeConfirm releases a pending pokemon to the trainer who accepted
+// it. From here on only they can have it, so the trade can't be cancelled.
+func commandTradeConfirm(c *config, args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return fmt.Errorf("usage: trade confirm <code|file> [file]")
+	}
+	acceptance := tradeAcceptance{}
+	by, err := openTrade(readTradeCode(args[2]), tradeAcceptPrefix, &acceptance)
+	if err != nil {
+		return err
+	}
+	offer, from, err := decodeTrade(acceptance.Offer)
+	if err != nil {
+		return err
+	}
+	if !from.Equal(myTrainerKey(c)) {
+		return fmt.Errorf("trade %s was offered by trainer %s, not you", offer.ID, trainerID(from))
+	}
+	if !by.Equal(offer.To) {
+		return fmt.Errorf("trade %s is for trainer %s, but trainer %s accepted it", offer.ID, trainerID(offer.To), trainerID(by))
+	}
+	pokemon, ok := c.PendingTrades[offer.ID]
+	if !ok {
+		return fmt.Errorf("there is no pending trade %s", offer.ID)
+	}
+	release, err := sealTrade(c.TrainerKey, tradeReleasePrefix, offer)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Released %s to trainer %s. Send them this code:\n", pokemon.Name, trainerID(by))
+	if err := giveTradeCode(c, release, optionalArg(args, 3)); err != nil {
+		return err
+	}
+	delete(c.PendingTrades, offer.ID)
+	return nil
+}
+
+func commandTradeReceive(c *config, args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: trade receive <code|file>")
+	}
+	offer, from, err := decodeOffer(readTradeCode(args[2]), tradeReleasePrefix)
+	if err != nil {
+		return err
+	}
+	if !offer.To.Equal(myTrainerKey(c)) {
+		return fmt.Errorf("trade %s is for trainer %s, not you", offer.ID, trainerID(offer.To))
+	}
+	if c.AcceptedTrades[offer.ID] {
+		return fmt.Errorf("you have already received trade %s", offer.ID)
+	}
+	caught, err := caughtFromTrade(c, offer.Pokemon)
+	if err != nil {
+		return fmt.Errorf("the trade is not a legal pokemon: %v", err)
+	}
+	fmt.Fprintf(c.Out, "Received %s (Lv. %d) from trainer %s!\n", caught.Name, caught.Level, trainerID(from))
+	if err := evolveOnTrade(c, caught); err != nil {
+		return err
+	}
+	if !confirmReplace(c, caught.Name) {
+		return fmt.Errorf("you kept your %s, trade %s was not received", caught.Name, offer.ID)
+	}
+	c.AcceptedTrades[offer.ID] = true
+	markSeen(c, caught.Species.Name)
+	addCaught(c, caught)
+	return nil
+}
+
+// commandTradeCancel takes back a pokemon that hasn't been released. Without
+// a release nobody can receive it, so it is only ever in one place.
+func commandTradeCancel(c *config, args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: trade cancel <trade-id>")
+	}
+	pokemon, ok := c.PendingTrades[args[2]]
+	if !ok {
+		return fmt.Errorf("there is no pending trade %s", args[2])
+	}
+	if !confirmReplace(c, pokemon.Name) {
+		return fmt.Errorf("you kept your %s, trade %s is still pending", pokemon.Name, args[2])
+	}
+	delete(c.PendingTrades, args[2])
+	c.Pokedex[pokemon.Name] = pokemon
+	addToParty(c, pokemon.Name)
+	fmt.Fprintf(c.Out, "%s came back. Trade %s can no longer be confirmed.\n", pokemon.Name, args[2])
+	return nil
+}
+
+func commandTrade(c *config, args []string) error {
+	if len(args) == 1 {
+		fmt.Fprintf(c.Out, "Your trainer key is %s\n", hex.EncodeToString(myTrainerKey(c)))
+		fmt.Fprintln(c.Out, "Trainers need it to offer you a pokemon.")
+		if len(c.PendingTrades) == 0 {
+			fmt.Fprintln(c.Out, "You have no pending trades.")
+			return nil
+		}
+		ids := []string{}
+		for id := range c.PendingTrades {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		fmt.Fprintln(c.Out, "Pending trades:")
+		for _, id := range ids {
+			fmt.Fprintf(c.Out, "- %s: %s (Lv. %d)\n", id, c.PendingTrades[id].Name, c.PendingTrades[id].Level)
+		}
+		return nil
+	}
+	// Only file paths keep their case; keys and codes are read in either.
+	args[1] = strings.ToLower(args[1])
+	if len(args) > 2 && (args[1] == "offer" || args[1] == "cancel") {
+		args[2] = strings.ToLower(args[2])
+	}
+	switch args[1] {
+	case "offer":
+		return commandTradeOffer(c, args)
+	case "accept":
+		return commandTradeAccept(c, args)
+	case "confirm":
+		return commandTradeConfirm(c, args)
+	case "receive":
+		return commandTradeReceive(c, args)
+	case "cancel":
+		return commandTradeCancel(c, args)
+	}
+	return fmt.Errorf("unknown trade action %s, use offer, accept, confirm, receive or cancel", args[1])
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glitchdawg/pokedex/internal/pokecache"
+)
+
+func TestTradeCodeRoundTrip(t *testing.T) {
+	key := newTrainerKey()
+	offer := tradeOffer{
+		ID:        newTradeID(),
+		OfferedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:        newTrainerKey().Public().(ed25519.PublicKey),
+		Pokemon: tradedPokemon{
+			Name:       "kadabra",
+			Level:      23,
+			KnownMoves: []string{"confusion", "teleport"},
+			IVs:        map[string]int{"special-attack": 31},
+			Nature:     "modest",
+		},
+	}
+	code, err := encodeTrade(key, offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != strings.ToLower(code) {
+		t.Error("expected a lowercase code")
+	}
+	decoded, from, err := decodeTrade(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(key.Public()) {
+		t.Error("expected the code to carry the sender's key")
+	}
+	if decoded.ID != offer.ID || !decoded.To.Equal(offer.To) || decoded.Pokemon.Name != "kadabra" || decoded.Pokemon.IVs["special-attack"] != 31 || !decoded.OfferedAt.Equal(offer.OfferedAt) {
+		t.Errorf("unexpected offer: %+v", decoded)
+	}
+
+	// Codes pasted across lines or in capitals still read.
+	if _, _, err := decodeTrade(strings.ToUpper(code[:40]) + "\n  " + code[40:]); err != nil {
+		t.Errorf("expected a reformatted code to decode, got %v", err)
+	}
+}
+
+func TestTradeCodeTampering(t *testing.T) {
+	code, err := encodeTrade(newTrainerKey(), tradeOffer{ID: "1", Pokemon: tradedPokemon{Name: "kadabra"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(code) - 5
+	flipped := code[:last] + string("ab"[(strings.IndexByte("ab", code[last])+1)%2]) + code[last+1:]
+	cases := map[string]string{
+		flipped:   "tampered",
+		"pkx2!!":  "damaged",
+		"pkx2abc": "damaged",
+		"pkr1abc": "this is a trade release, not a trade offer",
+		"hello":   "not a trade offer code",
+	}
+	for code, msg := range cases {
+		_, _, err := decodeTrade(code)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%.20q: expected an error containing %q, got %v", code, msg, err)
+		}
+	}
+}
+
+const testEvolutionChain = `{"chain": {
+	"species": {"name": "abra"},
+	"evolves_to": [{
+		"species": {"name": "kadabra"},
+		"evolution_details": [{"trigger": {"name": "level-up"}}],
+		"evolves_to": [{
+			"species": {"name": "alakazam"},
+			"evolution_details": [{"trigger": {"name": "trade"}, "held_item": null}]
+		}]
+	}, {
+		"species": {"name": "onix"},
+		"evolves_to": [{
+			"species": {"name": "steelix"},
+			"evolution_details": [{"trigger": {"name": "trade"}, "held_item": {"name": "metal-coat"}}]
+		}]
+	}, {
+		"species": {"name": "karrablast"},
+		"evolves_to": [{
+			"species": {"name": "escavalier"},
+			"evolution_details": [{"trigger": {"name": "trade"}, "trade_species": {"name": "shelmet"}}]
+		}]
+	}]
+}}`
+
+func TestTradeEvolution(t *testing.T) {
+	chain := EvolutionChain{}
+	if err := json.Unmarshal([]byte(testEvolutionChain), &chain); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		species, item string
+		into          string
+		consumes      bool
+	}{
+		{species: "kadabra", into: "alakazam"},
+		{species: "kadabra", item: "oran-berry", into: "alakazam"},
+		{species: "kadabra", item: "everstone"},
+		{species: "abra"},
+		{species: "onix"},
+		{species: "onix", item: "metal-coat", into: "steelix", consumes: true},
+		{species: "karrablast"},
+		{species: "alakazam"},
+	}
+	for _, c := range cases {
+		into, consumes, ok := tradeEvolution(chain.Chain, c.species, c.item)
+		if into != c.into || consumes != c.consumes || ok != (c.into != "") {
+			t.Errorf("%s holding %q: expected %q (consumes %v), got %q (consumes %v, %v)", c.species, c.item, c.into, c.consumes, into, consumes, ok)
+		}
+	}
+}
+
+func TestTradesSurviveSaving(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	c := &config{SavePath: path}
+	if err := loadGame(c); err != nil {
+		t.Fatal(err)
+	}
+	c.PendingTrades["abc"] = &CaughtPokemon{Pokemon: Pokemon{Name: "kadabra"}, Level: 23}
+	c.AcceptedTrades["def"] = true
+	if err := saveGame(c); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &config{SavePath: path}
+	if err := loadGame(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.TrainerKey.Equal(c.TrainerKey) {
+		t.Error("expected the trainer key to be kept")
+	}
+	if loaded.PendingTrades["abc"] == nil || !loaded.AcceptedTrades["def"] {
+		t.Errorf("unexpected trades after loading: %v %v", loaded.PendingTrades, loaded.AcceptedTrades)
+	}
+}
+
+func TestIllegalTradesAreRejected(t *testing.T) {
+	key := newTrainerKey()
+	legal := tradedPokemon{Name: "kadabra", Level: 23, Nature: "modest", IVs: map[string]int{"speed": 31}, EVs: map[string]int{"special-attack": 252}}
+	cases := map[string]func(p *tradedPokemon){
+		"level":  func(p *tradedPokemon) { p.Level = 101 },
+		"nature": func(p *tradedPokemon) { p.Nature = "sneaky" },
+		"IV":     func(p *tradedPokemon) { p.IVs = map[string]int{"attack": 32} },
+		"EVs":    func(p *tradedPokemon) { p.EVs = map[string]int{"attack": 253} },
+		"510":    func(p *tradedPokemon) { p.EVs = map[string]int{"attack": 252, "speed": 252, "hp": 8} },
+		"moves":  func(p *tradedPokemon) { p.KnownMoves = []string{"a", "b", "c", "d", "e"} },
+	}
+	for msg, cheat := range cases {
+		p := legal
+		cheat(&p)
+		code, err := encodeTrade(key, tradeOffer{ID: "1", To: key.Public().(ed25519.PublicKey), Pokemon: p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := decodeTrade(code); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected an error containing %q, got %v", msg, err)
+		}
+	}
+}
+
+// addTestKadabra puts what trading a kadabra fetches in the cache. Its
+// growth rate has level cubed experience points at every level.
+func addTestKadabra(t *testing.T, cache *pokecache.Cache) {
+	t.Helper()
+	cache.Add("https://pokeapi.co/api/v2/pokemon/kadabra", []byte(`{
+		"name": "kadabra",
+		"abilities": [{"ability": {"name": "synchronize"}}],
+		"moves": [{"move": {"name": "confusion"}}],
+		"species": {"name": "kadabra", "url": "https://pokeapi.co/api/v2/pokemon-species/64/"}
+	}`))
+	cache.Add("https://pokeapi.co/api/v2/pokemon-species/64/", []byte(`{
+		"name": "kadabra",
+		"growth_rate": {"name": "medium"},
+		"evolution_chain": {"url": "https://pokeapi.co/api/v2/evolution-chain/26/"}
+	}`))
+	cache.Add("https://pokeapi.co/api/v2/evolution-chain/26/", []byte(`{"chain": {"species": {"name": "kadabra"}}}`))
+	growth := GrowthRate{Name: "medium"}
+	for level := 1; level <= maxLevel; level++ {
+		growth.Levels = append(growth.Levels, struct {
+			Experience int `json:"experience"`
+			Level      int `json:"level"`
+		}{level * level * level, level})
+	}
+	data, err := json.Marshal(growth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Add("https://pokeapi.co/api/v2/growth-rate/medium", data)
+}
+
+func TestCaughtFromTrade(t *testing.T) {
+	c := &config{Cache: pokecache.NewCache(time.Hour)}
+	addTestKadabra(t, c.Cache)
+
+	legal := tradedPokemon{Name: "kadabra", Level: 23, Experience: 23 * 23 * 23, GrowthRate: "fast", Ability: "synchronize", KnownMoves: []string{"confusion"}, Nature: "modest", Legendary: true}
+	caught, err := caughtFromTrade(c, legal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caught.Legendary || caught.GrowthRate != "medium" {
+		t.Errorf("expected the species to come from PokeAPI, got legendary %v and growth rate %s", caught.Legendary, caught.GrowthRate)
+	}
+
+	cases := map[string]func(p *tradedPokemon){
+		"ability":     func(p *tradedPokemon) { p.Ability = "wonder-guard" },
+		"can't learn": func(p *tradedPokemon) { p.KnownMoves = []string{"spore"} },
+		"Exp. Points": func(p *tradedPokemon) { p.Experience = 100 * 100 * 100 },
+	}
+	for msg, cheat := range cases {
+		p := legal
+		cheat(&p)
+		if _, err := caughtFromTrade(c, p); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected an error containing %q, got %v", msg, err)
+		}
+	}
+}
+
+// testTrainer is a fresh save sharing cache with the other test trainers.
+func testTrainer(t *testing.T, cache *pokecache.Cache) (*config, *bytes.Buffer) {
+	t.Helper()
+	out := &bytes.Buffer{}
+	c := &config{Cache: cache, SavePath: filepath.Join(t.TempDir(), "save.json"), Out: out}
+	if err := loadGame(c); err != nil {
+		t.Fatal(err)
+	}
+	return c, out
+}
+
+// lastLine is the code a trade command just printed.
+func lastLine(out *bytes.Buffer) string {
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	return lines[len(lines)-1]
+}
+
+func TestTrade(t *testing.T) {
+	cache := pokecache.NewCache(time.Hour)
+	addTestKadabra(t, cache)
+	alice, aliceOut := testTrainer(t, cache)
+	bob, bobOut := testTrainer(t, cache)
+	eve, _ := testTrainer(t, cache)
+	alice.Pokedex["kadabra"] = &CaughtPokemon{Pokemon: Pokemon{Name: "kadabra"}, CaughtID: 1, Level: 23, Experience: 23 * 23 * 23, Ability: "synchronize", Nature: "modest"}
+	bobKey := hex.EncodeToString(myTrainerKey(bob))
+
+	if err := commandTrade(alice, []string{"trade", "offer", "kadabra", bobKey}); err != nil {
+		t.Fatal(err)
+	}
+	offer := lastLine(aliceOut)
+	if alice.Pokedex["kadabra"] != nil || len(alice.PendingTrades) != 1 {
+		t.Fatal("expected kadabra to wait in the pending trades")
+	}
+	if err := commandTrade(eve, []string{"trade", "accept", offer}); err == nil || !strings.Contains(err.Error(), "not you") {
+		t.Errorf("expected an offer to bob to be refused by eve, got %v", err)
+	}
+	if err := commandTrade(bob, []string{"trade", "receive", offer}); err == nil {
+		t.Error("expected an offer not to be receivable")
+	}
+
+	if err := commandTrade(bob, []string{"trade", "accept", offer}); err != nil {
+		t.Fatal(err)
+	}
+	acceptance := lastLine(bobOut)
+	if bob.Pokedex["kadabra"] != nil {
+		t.Error("expected bob to wait for alice's release")
+	}
+	if err := commandTrade(alice, []string{"trade", "confirm", acceptance}); err != nil {
+		t.Fatal(err)
+	}
+	release := lastLine(aliceOut)
+	if len(alice.PendingTrades) != 0 {
+		t.Error("expected confirming to release kadabra")
+	}
+	if err := commandTrade(alice, []string{"trade", "confirm", acceptance}); err == nil {
+		t.Error("expected an acceptance to be confirmed once")
+	}
+	if err := commandTrade(eve, []string{"trade", "receive", release}); err == nil || !strings.Contains(err.Error(), "not you") {
+		t.Errorf("expected a release to bob to be refused by eve, got %v", err)
+	}
+
+	if err := commandTrade(bob, []string{"trade", "receive", release}); err != nil {
+		t.Fatal(err)
+	}
+	if received := bob.Pokedex["kadabra"]; received == nil || received.Level != 23 {
+		t.Fatalf("expected bob to have kadabra, got %+v", received)
+	}
+	if err := commandTrade(bob, []string{"trade", "receive", release}); err == nil {
+		t.Error("expected a release to be received once")
+	}
+}
+
+func TestTradeCancel(t *testing.T) {
+	cache := pokecache.NewCache(time.Hour)
+	addTestKadabra(t, cache)
+	alice, aliceOut := testTrainer(t, cache)
+	bob, bobOut := testTrainer(t, cache)
+	alice.Pokedex["kadabra"] = &CaughtPokemon{Pokemon: Pokemon{Name: "kadabra"}, CaughtID: 1, Level: 23, Experience: 23 * 23 * 23, Ability: "synchronize", Nature: "modest"}
+
+	if err := commandTrade(alice, []string{"trade", "offer", "kadabra", hex.EncodeToString(myTrainerKey(bob))}); err != nil {
+		t.Fatal(err)
+	}
+	offer := lastLine(aliceOut)
+	if err := commandTrade(bob, []string{"trade", "accept", offer}); err != nil {
+		t.Fatal(err)
+	}
+	acceptance := lastLine(bobOut)
+	offered, _, err := decodeTrade(offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := commandTrade(alice, []string{"trade", "cancel", offered.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if alice.Pokedex["kadabra"] == nil {
+		t.Fatal("expected kadabra to come back")
+	}
+	if err := commandTrade(alice, []string{"trade", "confirm", acceptance}); err == nil {
+		t.Error("expected a cancelled trade not to be confirmed")
+	}
+}
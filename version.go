@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+)
+
+// The first pages of the map are Sinnoh, so encounters and learnsets default
+// to Diamond.
+const (
+	defaultVersion      = "diamond"
+	defaultVersionGroup = "diamond-pearl"
+)
+
+func commandVersion(c *config, args []string) error {
+	if len(args) == 1 {
+		fmt.Fprintf(c.Out, "Playing Pokemon %s (%s)\n", c.Version, c.VersionGroup)
+		return nil
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("too many arguments, please provide only one version name")
+	}
+	version := GameVersion{}
+	url := fmt.Sprintf("https://pokeapi.co/api/v2/version/%s", args[1])
+	if err := fetchJSON(c, url, &version); err != nil {
+		return fmt.Errorf("failed to fetch version %s: %v", args[1], err)
+	}
+	c.Version = version.Name
+	c.VersionGroup = version.VersionGroup.Name
+	fmt.Fprintf(c.Out, "Now playing Pokemon %s (%s)\n", c.Version, c.VersionGroup)
+	return nil
+}
@@ -0,0 +1,221 @@
+// The dashboard talks to the same JSON API as every other client of
+// `pokedex serve`, so anything it does is also possible from a script.
+
+const statNames = ["hp", "attack", "defense", "special-attack", "special-defense", "speed"];
+const statLabels = {
+  "hp": "HP",
+  "attack": "Attack",
+  "defense": "Defense",
+  "special-attack": "Sp. Atk",
+  "special-defense": "Sp. Def",
+  "speed": "Speed",
+};
+
+let pokemon = [];
+let areaOffset = 0;
+
+const $ = (id) => document.getElementById(id);
+
+async function api(path, body) {
+  const options = body === undefined ? {} : {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify(body),
+  };
+  const response = await fetch(path, options);
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || response.statusText);
+  }
+  return data;
+}
+
+function setStatus(id, message, isError) {
+  const status = $(id);
+  status.textContent = message;
+  status.classList.toggle("error", Boolean(isError));
+}
+
+async function loadTrainer() {
+  const trainer = await api("/api/trainer");
+  const balls = Object.keys(trainer.bag).filter((item) => item.endsWith("-ball"));
+  $("trainer").textContent = `Trainer ${trainer.trainer} · ₽${trainer.money} · ${trainer.caught} caught · ${trainer.seen} seen`;
+  const ball = $("ball");
+  const selected = ball.value;
+  ball.replaceChildren(...balls.map((name) => new Option(`${name} x${trainer.bag[name]}`, name)));
+  if (balls.includes(selected)) {
+    ball.value = selected;
+  }
+}
+
+async function loadPokedex() {
+  const query = $("query").value.trim();
+  try {
+    pokemon = await api("/api/pokemon?q=" + encodeURIComponent(query));
+    setStatus("pokedex-status", "");
+  } catch (err) {
+    pokemon = [];
+    setStatus("pokedex-status", err.message, true);
+  }
+  const types = new Set(pokemon.flatMap((p) => p.types));
+  const type = $("type");
+  const selected = type.value;
+  type.replaceChildren(new Option("Any type", ""), ...[...types].sort().map((t) => new Option(t, t)));
+  type.value = types.has(selected) ? selected : "";
+  renderPokedex();
+}
+
+function total(p) {
+  return statNames.reduce((sum, stat) => sum + (p.stats[stat] || 0), 0);
+}
+
+function renderPokedex() {
+  const search = $("search").value.trim().toLowerCase();
+  const type = $("type").value;
+  const sort = $("sort").value;
+  const shown = pokemon.filter((p) =>
+    p.name.includes(search) &&
+    (!type || p.types.includes(type)) &&
+    (!$("party").checked || p.in_party) &&
+    (!$("shiny").checked || p.shiny));
+  shown.sort((a, b) => {
+    switch (sort) {
+      case "name": return a.name.localeCompare(b.name);
+      case "number": return a.number - b.number;
+      case "level": return b.level - a.level;
+      case "total": return total(b) - total(a);
+      default: return a.caught_id - b.caught_id;
+    }
+  });
+  const highest = Math.max(1, ...shown.flatMap((p) => statNames.map((s) => p.stats[s] || 0)));
+  $("cards").replaceChildren(...shown.map((p) => card(p, highest)));
+  if (!$("pokedex-status").classList.contains("error")) {
+    setStatus("pokedex-status", `${shown.length} of ${pokemon.length} pokemon`);
+  }
+}
+
+function card(p, highest) {
+  const node = $("card").content.firstElementChild.cloneNode(true);
+  const img = node.querySelector("img");
+  if (p.sprite) {
+    img.src = p.sprite;
+  }
+  img.alt = p.name;
+  node.querySelector("h3").textContent = (p.shiny ? "★ " : "") + p.name;
+  const details = [`#${String(p.number).padStart(3, "0")}`, `Lv. ${p.level}`, p.nature, p.ability];
+  if (p.held_item) {
+    details.push(`holding ${p.held_item}`);
+  }
+  if (p.in_party) {
+    details.push("in party");
+  }
+  node.querySelector(".meta").textContent = details.filter(Boolean).join(" · ");
+  node.querySelector(".types").replaceChildren(...p.types.map((t) => {
+    const span = document.createElement("span");
+    span.className = "type";
+    span.textContent = t;
+    return span;
+  }));
+  node.querySelector(".stats").replaceChildren(...statNames.map((stat) => {
+    const row = document.createElement("tr");
+    const label = document.createElement("td");
+    const value = document.createElement("td");
+    const bar = document.createElement("td");
+    const fill = document.createElement("div");
+    label.textContent = statLabels[stat];
+    value.textContent = p.stats[stat] || 0;
+    fill.className = "bar";
+    fill.style.width = `${100 * (p.stats[stat] || 0) / highest}%`;
+    bar.append(fill);
+    row.append(label, value, bar);
+    return row;
+  }));
+  return node;
+}
+
+async function loadAreas(offset) {
+  try {
+    const page = await api(`/api/locations?offset=${offset}`);
+    areaOffset = page.offset;
+    $("previous").disabled = page.previous === null;
+    $("next").disabled = page.next === null;
+    $("previous").dataset.offset = page.previous;
+    $("next").dataset.offset = page.next;
+    $("page").textContent = `${page.offset + 1}-${page.offset + page.areas.length} of ${page.count}`;
+    $("areas").replaceChildren(...page.areas.map((name) => {
+      const item = document.createElement("li");
+      item.textContent = name;
+      item.addEventListener("click", () => explore(name));
+      return item;
+    }));
+  } catch (err) {
+    setStatus("explore-status", err.message, true);
+  }
+}
+
+async function explore(area) {
+  $("area-name").textContent = area;
+  $("encounters").replaceChildren();
+  setStatus("explore-status", "Exploring...");
+  try {
+    const result = await api("/api/explore", {area});
+    setStatus("explore-status", `Found ${result.pokemon.length} pokemon and ₽${result.money_found}.`);
+    $("encounters").replaceChildren(...result.pokemon.map((name) => {
+      const item = document.createElement("li");
+      const label = document.createElement("span");
+      const button = document.createElement("button");
+      label.textContent = name;
+      button.textContent = "Catch";
+      button.addEventListener("click", () => catchPokemon(name, button));
+      item.append(label, button);
+      return item;
+    }));
+    loadTrainer();
+  } catch (err) {
+    setStatus("explore-status", err.message, true);
+  }
+}
+
+async function catchPokemon(name, button) {
+  button.disabled = true;
+  try {
+    const result = await api("/api/catch", {pokemon: name, ball: $("ball").value || "poke-ball"});
+    if (result.caught) {
+      setStatus("explore-status", `${result.pokemon.shiny ? "A shiny " : ""}${name} was caught! (Lv. ${result.pokemon.level})`);
+      loadPokedex();
+    } else {
+      setStatus("explore-status", `${name} escaped!`);
+    }
+  } catch (err) {
+    setStatus("explore-status", err.message, true);
+  }
+  button.disabled = false;
+  loadTrainer();
+}
+
+function showTab(name) {
+  for (const tab of document.querySelectorAll(".tab")) {
+    tab.classList.toggle("active", tab.dataset.tab === name);
+  }
+  for (const panel of document.querySelectorAll(".panel")) {
+    panel.hidden = panel.id !== name;
+  }
+}
+
+for (const tab of document.querySelectorAll(".tab")) {
+  tab.addEventListener("click", () => showTab(tab.dataset.tab));
+}
+for (const id of ["search", "type", "sort", "party", "shiny"]) {
+  $(id).addEventListener("input", renderPokedex);
+}
+$("filters").addEventListener("submit", (event) => {
+  event.preventDefault();
+  loadPokedex();
+});
+$("query").addEventListener("change", loadPokedex);
+$("previous").addEventListener("click", () => loadAreas(Number($("previous").dataset.offset)));
+$("next").addEventListener("click", () => loadAreas(Number($("next").dataset.offset)));
+
+loadTrainer();
+loadPokedex();
+loadAreas(areaOffset);